@@ -48,15 +48,15 @@ func (h *Handlers) GetSalesAnalytics(c *fiber.Ctx) error {
 }
 
 func (h *Handlers) calculateAnalytics(orders []models.Order) map[string]interface{} {
-	totalRevenue := 0.0
+	var totalRevenue models.Money
 	totalOrders := len(orders)
 	completedOrders := 0
 	cancelledOrders := 0
 
 	itemsSold := make(map[string]int)
 	hourlyOrders := make(map[int]int)
-	dailyRevenue := make(map[string]float64)
-	categoryRevenue := make(map[string]float64)
+	dailyRevenue := make(map[string]models.Money)
+	categoryRevenue := make(map[string]models.Money)
 
 	for _, order := range orders {
 		if order.Status == models.OrderStatusCompleted {
@@ -91,7 +91,7 @@ func (h *Handlers) calculateAnalytics(orders []models.Order) map[string]interfac
 	// Calculate average order value
 	avgOrderValue := 0.0
 	if completedOrders > 0 {
-		avgOrderValue = totalRevenue / float64(completedOrders)
+		avgOrderValue = totalRevenue.Float64() / float64(completedOrders)
 	}
 
 	// Calculate completion rate
@@ -183,10 +183,10 @@ func (h *Handlers) getPeakHours(hourlyOrders map[int]int) []map[string]interface
 	return result
 }
 
-func (h *Handlers) formatDailyRevenue(dailyRevenue map[string]float64) []map[string]interface{} {
+func (h *Handlers) formatDailyRevenue(dailyRevenue map[string]models.Money) []map[string]interface{} {
 	type dailyData struct {
 		Date    string
-		Revenue float64
+		Revenue models.Money
 	}
 
 	var days []dailyData
@@ -210,10 +210,10 @@ func (h *Handlers) formatDailyRevenue(dailyRevenue map[string]float64) []map[str
 	return result
 }
 
-func (h *Handlers) formatCategoryRevenue(categoryRevenue map[string]float64) []map[string]interface{} {
+func (h *Handlers) formatCategoryRevenue(categoryRevenue map[string]models.Money) []map[string]interface{} {
 	type categoryData struct {
 		Category string
-		Revenue  float64
+		Revenue  models.Money
 	}
 
 	var categories []categoryData
@@ -245,7 +245,7 @@ func (h *Handlers) GetTableAnalytics(c *fiber.Ctx) error {
 		TableID     uint
 		TableNumber string
 		OrderCount  int64
-		Revenue     float64
+		Revenue     models.Money
 	}
 
 	h.DB.Table("orders").
@@ -271,7 +271,7 @@ func (h *Handlers) GetMenuPerformance(c *fiber.Ctx) error {
 		CategoryName string
 		OrderCount   int64
 		Quantity     int64
-		Revenue      float64
+		Revenue      models.Money
 	}
 
 	h.DB.Table("order_items").
@@ -296,4 +296,98 @@ func (h *Handlers) GetMenuPerformance(c *fiber.Ctx) error {
 		"message": "Menu performance retrieved",
 		"data":    itemStats,
 	})
-}
\ No newline at end of file
+}
+// GetTipAnalytics reports the tips earned in each staff shift that started
+// in the date range, and their total. A tip counts for the shift in which its
+// order was served by that staff member.
+func (h *Handlers) GetTipAnalytics(c *fiber.Ctx) error {
+	// Parse date range
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	if startDate == "" || endDate == "" {
+		// Default to last 30 days
+		end := time.Now()
+		start := end.AddDate(0, 0, -30)
+		startDate = start.Format("2006-01-02")
+		endDate = end.Format("2006-01-02")
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid start_date, expected YYYY-MM-DD",
+		})
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid end_date, expected YYYY-MM-DD",
+		})
+	}
+	end = end.Add(24 * time.Hour) // Include the entire end date
+
+	tipStatuses := []string{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded}
+
+	var shiftTips []struct {
+		ShiftID        uint
+		StaffID        uint
+		StaffName      string
+		StartedAt      time.Time
+		EndedAt        *time.Time
+		TippedPayments int64
+		Tips           models.Money
+	}
+
+	err = h.DB.Table("staff_shifts").
+		Select(`
+			staff_shifts.id as shift_id,
+			staff_shifts.staff_id,
+			staffs.full_name as staff_name,
+			staff_shifts.started_at,
+			staff_shifts.ended_at,
+			COUNT(payments.id) as tipped_payments,
+			COALESCE(SUM(payments.tip_amount), 0) as tips
+		`).
+		Joins("JOIN staffs ON staffs.id = staff_shifts.staff_id").
+		// Each order's tips go to the shift of whoever served it last, so an
+		// order served again after a correction is not credited twice
+		Joins(`LEFT JOIN (
+			SELECT DISTINCT ON (order_id) order_id, staff_id, created_at
+			FROM order_events
+			WHERE order_item_id IS NULL AND to_status = ? AND staff_id IS NOT NULL
+			ORDER BY order_id, created_at DESC, id DESC
+		) AS served ON served.staff_id = staff_shifts.staff_id
+			AND served.created_at >= staff_shifts.started_at
+			AND (staff_shifts.ended_at IS NULL OR served.created_at < staff_shifts.ended_at)`,
+			models.OrderStatusServed).
+		Joins("LEFT JOIN payments ON payments.order_id = served.order_id AND payments.tip_amount > 0 AND payments.status IN ? AND payments.deleted_at IS NULL",
+			tipStatuses).
+		Where("staff_shifts.started_at >= ? AND staff_shifts.started_at < ?", start, end).
+		Group("staff_shifts.id, staffs.full_name").
+		Order("staff_shifts.started_at").
+		Scan(&shiftTips).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get analytics data",
+		})
+	}
+
+	// Totalled from the shifts so it covers the same tips as the rows
+	var totalTips models.Money
+	for _, shift := range shiftTips {
+		totalTips += shift.Tips
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tip analytics retrieved",
+		"data": fiber.Map{
+			"total_tips": totalTips,
+			"shifts":     shiftTips,
+		},
+	})
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"lendral3n/ordering-system/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type StaffLoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (h *Handlers) StaffLogin(c *fiber.Ctx) error {
+	var req StaffLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if req.Username == "" || req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Username and password are required",
+		})
+	}
+
+	// Use the same error for unknown users and wrong passwords
+	staff, err := h.StaffRepository.GetByUsername(req.Username)
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(staff.PasswordHash), []byte(req.Password)) != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid username or password",
+		})
+	}
+
+	if !staff.IsActive {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Staff account is not active",
+		})
+	}
+
+	tokens, err := h.TokenService.GenerateTokenPair(staff)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to generate token",
+		})
+	}
+
+	h.StaffRepository.UpdateLastLogin(staff.ID)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Login successful",
+		"data": fiber.Map{
+			"tokens": tokens,
+			"staff":  staff,
+		},
+	})
+}
+
+func (h *Handlers) RefreshStaffToken(c *fiber.Ctx) error {
+	var req RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Refresh token required",
+		})
+	}
+
+	claims, err := h.TokenService.ParseRefreshToken(req.RefreshToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid or expired refresh token",
+		})
+	}
+
+	// Reload staff so role changes and deactivation take effect on refresh
+	staff, err := h.StaffRepository.GetByID(claims.StaffID)
+	if err != nil || !staff.IsActive {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Staff account is not active",
+		})
+	}
+
+	tokens, err := h.TokenService.GenerateTokenPair(staff)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to generate token",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Token refreshed",
+		"data":    tokens,
+	})
+}
+
+func (h *Handlers) GetStaffProfile(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Profile retrieved",
+		"data":    middleware.CurrentStaff(c),
+	})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
+	"lendral3n/ordering-system/internal/services/orderstatus"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *Handlers) CancelCustomerOrder(c *fiber.Ctx) error {
+	// Validate session
+	sessionToken := c.Get("X-Session-Token")
+	if sessionToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session token required",
+		})
+	}
+
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	var session models.CustomerSession
+	if err := h.DB.Where("session_token = ?", sessionToken).First(&session).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid session",
+		})
+	}
+
+	var req CancelOrderRequest
+	c.BodyParser(&req)
+
+	reason := "Cancelled by customer"
+	if req.Reason != "" {
+		reason = req.Reason
+	}
+
+	// Customers may only cancel their own orders before the kitchen picks them up
+	return h.cancelOrder(c, uint(orderID), orderstatus.Change{Reason: reason}, func(order *models.Order) error {
+		if order.SessionID != session.ID {
+			return fiber.NewError(fiber.StatusForbidden, "Unauthorized")
+		}
+		if order.Status != models.OrderStatusPending {
+			return fiber.NewError(fiber.StatusBadRequest, "Order can no longer be cancelled")
+		}
+		return nil
+	})
+}
+
+// Staff endpoints
+func (h *Handlers) CancelOrder(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	var req CancelOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	return h.cancelStaffOrder(c, uint(orderID), req.Reason)
+}
+
+func (h *Handlers) cancelStaffOrder(c *fiber.Ctx, orderID uint, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Cancellation reason is required",
+		})
+	}
+
+	return h.cancelOrder(c, orderID, staffChange(c, reason), nil)
+}
+
+func (h *Handlers) cancelOrder(c *fiber.Ctx, orderID uint, change orderstatus.Change, check func(order *models.Order) error) error {
+	// Stations are resolved up front; once cancelled the items no longer map to a queue
+	stations, _ := h.KitchenService.OrderStations(orderID)
+
+	var order *models.Order
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = orderstatus.Cancel(tx, orderID, change, check)
+		if err != nil {
+			return err
+		}
+		return billing.VoidSplit(tx, orderID)
+	})
+
+	if err != nil {
+		if e, ok := err.(*fiber.Error); ok {
+			return c.Status(e.Code).JSON(fiber.Map{
+				"success": false,
+				"error":   e.Message,
+			})
+		}
+		return orderStatusError(c, err, "Order not found", "Failed to cancel order")
+	}
+
+	go h.NotificationHub.BroadcastOrderStatusUpdate(order)
+	for _, station := range stations {
+		go h.broadcastStationQueue(station)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Order cancelled",
+		"data":    h.settleCancelledOrder(order, change),
+	})
+}
+
+// settleCancelledOrder refunds whatever was paid for a cancelled order, in
+// full or in shares, and describes the outcome for the response.
+func (h *Handlers) settleCancelledOrder(order *models.Order, change orderstatus.Change) fiber.Map {
+	response := fiber.Map{
+		"order_id": order.ID,
+		"status":   order.Status,
+	}
+
+	refunded, err := h.refundCancelledOrder(order, change)
+	if err != nil {
+		log.Printf("Failed to refund cancelled order %s: %v", order.OrderNumber, err)
+		response["refund_error"] = "Refund failed, please retry from the payments page"
+	} else if refunded {
+		response["refunded"] = true
+	}
+
+	return response
+}
+
+// refundCancelledOrder refunds the remaining balance of every settled
+// payment of the order and reports whether there was anything to refund.
+func (h *Handlers) refundCancelledOrder(order *models.Order, change orderstatus.Change) (bool, error) {
+	var payments []models.Payment
+	err := h.DB.Where("order_id = ? AND status IN ?", order.ID, []string{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded}).
+		Order("created_at").
+		Find(&payments).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to get payments: %w", err)
+	}
+
+	for _, p := range payments {
+		if _, err := h.RefundService.Refund(p.ID, 0, change.Reason, change.StaffID); err != nil {
+			return true, err
+		}
+	}
+
+	return len(payments) > 0, nil
+}
@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"lendral3n/ordering-system/internal/config"
+	"lendral3n/ordering-system/internal/repository"
+	"lendral3n/ordering-system/internal/services/auth"
+	"lendral3n/ordering-system/internal/services/invoice"
+	"lendral3n/ordering-system/internal/services/kitchen"
 	"lendral3n/ordering-system/internal/services/media"
 	"lendral3n/ordering-system/internal/services/notification"
 	"lendral3n/ordering-system/internal/services/payment"
+	"lendral3n/ordering-system/internal/services/printer"
 	"lendral3n/ordering-system/internal/services/qrcode"
 
 	"gorm.io/gorm"
@@ -13,26 +18,44 @@ import (
 type Handlers struct {
 	DB                *gorm.DB
 	CloudinaryService *media.CloudinaryService
-	MidtransService   *payment.MidtransService
+	PaymentProvider   payment.PaymentProvider
+	RefundService     *payment.RefundService
 	QRService         *qrcode.QRService
+	InvoiceService    *invoice.Service
+	ReceiptPrinter    printer.Sink
+	KitchenService    *kitchen.Service
 	NotificationHub   *notification.Hub
+	TokenService      *auth.TokenService
+	StaffRepository   repository.StaffRepository
+	TableRepository   repository.TableRepository
 	Config            *config.Config
 }
 
 func NewHandlers(
 	db *gorm.DB,
 	cloudinaryService *media.CloudinaryService,
-	midtransService *payment.MidtransService,
+	paymentProvider payment.PaymentProvider,
 	qrService *qrcode.QRService,
+	invoiceService *invoice.Service,
+	receiptPrinter printer.Sink,
+	kitchenService *kitchen.Service,
 	notificationHub *notification.Hub,
+	tokenService *auth.TokenService,
 	config *config.Config,
 ) *Handlers {
 	return &Handlers{
 		DB:                db,
 		CloudinaryService: cloudinaryService,
-		MidtransService:   midtransService,
+		PaymentProvider:   paymentProvider,
+		RefundService:     payment.NewRefundService(db, paymentProvider),
 		QRService:         qrService,
+		InvoiceService:    invoiceService,
+		ReceiptPrinter:    receiptPrinter,
+		KitchenService:    kitchenService,
 		NotificationHub:   notificationHub,
+		TokenService:      tokenService,
+		StaffRepository:   repository.NewStaffRepository(db),
+		TableRepository:   repository.NewTableRepository(db),
 		Config:            config,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/invoice"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func (h *Handlers) GetCustomerInvoice(c *fiber.Ctx) error {
+	// Validate session
+	sessionToken := c.Get("X-Session-Token")
+	if sessionToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session token required",
+		})
+	}
+
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	var order models.Order
+	if err := h.DB.First(&order, orderID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order not found",
+		})
+	}
+
+	// Verify order belongs to session
+	var session models.CustomerSession
+	if err := h.DB.Where("session_token = ?", sessionToken).First(&session).Error; err != nil || session.ID != order.SessionID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Unauthorized",
+		})
+	}
+
+	return h.sendInvoice(c, order.ID)
+}
+
+// Staff endpoints
+func (h *Handlers) GetOrderInvoice(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	// format=json returns the invoice record without rendering the PDF
+	if c.Query("format") == "json" {
+		inv, err := h.InvoiceService.GetOrCreateInvoice(uint(orderID))
+		if err != nil {
+			return invoiceError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Invoice retrieved",
+			"data":    inv,
+		})
+	}
+
+	return h.sendInvoice(c, uint(orderID))
+}
+
+func (h *Handlers) sendInvoice(c *fiber.Ctx, orderID uint) error {
+	pdf, inv, err := h.InvoiceService.GenerateInvoice(orderID)
+	if err != nil {
+		return invoiceError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.pdf"`, inv.InvoiceNumber))
+	c.Set("X-Invoice-Number", inv.InvoiceNumber)
+	return c.Send(pdf)
+}
+
+func invoiceError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, invoice.ErrOrderNotPaid) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invoice is only available for paid orders",
+		})
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order not found",
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to generate invoice",
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/kitchen"
+	"lendral3n/ordering-system/internal/services/orderstatus"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Staff endpoints
+func (h *Handlers) GetKitchenStations(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Stations retrieved",
+		"data":    models.Stations,
+	})
+}
+
+func (h *Handlers) GetStationQueue(c *fiber.Ctx) error {
+	station := c.Params("station")
+	if !isValidStation(station) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Station not found",
+		})
+	}
+
+	queue, err := h.KitchenService.StationQueue(station)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get station queue",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Station queue retrieved",
+		"data":    queue,
+	})
+}
+
+func (h *Handlers) BumpOrderItem(c *fiber.Ctx) error {
+	return h.moveOrderItem(c, h.KitchenService.Bump, "Item bumped")
+}
+
+func (h *Handlers) RecallOrderItem(c *fiber.Ctx) error {
+	return h.moveOrderItem(c, h.KitchenService.Recall, "Item recalled")
+}
+
+func (h *Handlers) moveOrderItem(c *fiber.Ctx, move func(itemID uint, change orderstatus.Change) (*models.OrderItem, *models.Order, error), message string) error {
+	itemID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid item ID",
+		})
+	}
+
+	item, order, err := move(uint(itemID), staffChange(c, ""))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "Order item not found",
+			})
+		}
+
+		var transitionErr *orderstatus.TransitionError
+		if errors.Is(err, kitchen.ErrCannotBump) || errors.Is(err, kitchen.ErrCannotRecall) || errors.As(err, &transitionErr) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update item status",
+		})
+	}
+
+	go h.broadcastStationQueue(item.MenuItem.StationName())
+	if order != nil {
+		go h.NotificationHub.BroadcastOrderStatusUpdate(order)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    item,
+	})
+}
+
+// broadcastStationQueue pushes a fresh queue snapshot to the station's displays.
+func (h *Handlers) broadcastStationQueue(station string) {
+	queue, err := h.KitchenService.StationQueue(station)
+	if err != nil {
+		log.Printf("Failed to load %s queue: %v", station, err)
+		return
+	}
+	h.NotificationHub.BroadcastStationQueue(station, queue)
+}
+
+func (h *Handlers) broadcastOrderStations(orderID uint) {
+	stations, err := h.KitchenService.OrderStations(orderID)
+	if err != nil {
+		log.Printf("Failed to load stations for order %d: %v", orderID, err)
+		return
+	}
+	for _, station := range stations {
+		h.broadcastStationQueue(station)
+	}
+}
+
+func (h *Handlers) broadcastItemStation(itemID uint) {
+	station, err := h.KitchenService.ItemStation(itemID)
+	if err != nil {
+		log.Printf("Failed to load station for item %d: %v", itemID, err)
+		return
+	}
+	h.broadcastStationQueue(station)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/kitchen"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Staff endpoints
+func (h *Handlers) GetOrderTickets(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	tickets, err := h.KitchenService.GetOrderTickets(uint(orderID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get kitchen tickets",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Kitchen tickets retrieved",
+		"data":    tickets,
+	})
+}
+
+func (h *Handlers) ReprintKitchenTicket(c *fiber.Ctx) error {
+	ticketID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid ticket ID",
+		})
+	}
+
+	ticket, err := h.KitchenService.Reprint(uint(ticketID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "Kitchen ticket not found",
+			})
+		}
+
+		if errors.Is(err, kitchen.ErrNoPrinter) {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success": false,
+				"error":   "No printer configured for station",
+				"data":    ticket,
+			})
+		}
+
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to print kitchen ticket",
+			"data":    ticket,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Kitchen ticket reprinted",
+		"data":    ticket,
+	})
+}
+
+// printKitchenTickets prints the station tickets of a new order. Failures
+// are recorded on the ticket and can be retried with a reprint.
+func (h *Handlers) printKitchenTickets(orderID uint) {
+	if _, err := h.KitchenService.PrintOrderTickets(orderID); err != nil {
+		log.Printf("Failed to print kitchen tickets for order %d: %v", orderID, err)
+	}
+}
+
+func isValidStation(station string) bool {
+	for _, s := range models.Stations {
+		if s == station {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/middleware"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
+	"lendral3n/ordering-system/internal/services/payment"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ManualPaymentRequest struct {
+	OrderID        uint         `json:"order_id" validate:"required"`
+	Method         string       `json:"method" validate:"required"` // cash or edc
+	TenderedAmount models.Money `json:"tendered_amount"`            // cash only
+	ApprovalCode   string       `json:"approval_code"`              // EDC only
+	ShareID        uint         `json:"share_id"`                   // pays one share of a split bill
+}
+
+// Staff endpoints
+func (h *Handlers) RecordManualPayment(c *fiber.Ctx) error {
+	var req ManualPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	manual := payment.ManualPayment{
+		Method:         req.Method,
+		TenderedAmount: req.TenderedAmount,
+		ApprovalCode:   req.ApprovalCode,
+	}
+	if staff := middleware.CurrentStaff(c); staff != nil {
+		manual.StaffID = &staff.ID
+	}
+
+	var order models.Order
+	var paymentRecord *models.Payment
+	orderPaid := true
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, req.OrderID).Error; err != nil {
+			return err
+		}
+
+		if order.Status == models.OrderStatusCancelled {
+			return fiber.NewError(fiber.StatusBadRequest, "Order is cancelled")
+		}
+		if order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusRefunded || order.PaymentStatus == models.PaymentStatusPartiallyRefunded {
+			return fiber.NewError(fiber.StatusBadRequest, "Order already paid")
+		}
+
+		manual.Amount = order.GrandTotal
+		if req.ShareID != 0 {
+			share, err := billing.LockShare(tx, order.ID, req.ShareID)
+			if err != nil {
+				return err
+			}
+			manual.Amount = share.Amount
+			manual.SplitShareID = &share.ID
+		} else if split, err := billing.HasOpenSplit(tx, order.ID); err != nil {
+			return err
+		} else if split {
+			return fiber.NewError(fiber.StatusBadRequest, "Order is being paid in shares, pick a share to pay")
+		}
+
+		var err error
+		paymentRecord, err = payment.RecordManual(tx, &order, manual)
+		if err != nil {
+			return err
+		}
+
+		// The order is paid with its last share
+		if manual.SplitShareID != nil {
+			orderPaid, err = billing.UpdateShare(tx, *manual.SplitShareID, paymentRecord.ID, models.PaymentStatusPaid)
+			if err != nil {
+				return err
+			}
+			if !orderPaid {
+				return tx.Model(&order).Update("payment_status", models.PaymentStatusPending).Error
+			}
+		}
+
+		return tx.Model(&order).Updates(map[string]interface{}{
+			"payment_status": models.PaymentStatusPaid,
+			"payment_method": req.Method,
+		}).Error
+	})
+
+	if err != nil {
+		return manualPaymentError(c, err)
+	}
+
+	if !orderPaid {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Share payment recorded",
+			"data":    paymentRecord,
+		})
+	}
+
+	// Create notification
+	notification := models.Notification{
+		OrderID: &order.ID,
+		Type:    models.NotificationPaymentReceived,
+		Message: fmt.Sprintf("Payment received for order #%s", order.OrderNumber),
+	}
+	h.DB.Create(&notification)
+
+	go h.NotificationHub.BroadcastPaymentReceived(paymentRecord, &order)
+	go h.autoPrintReceipt(order.ID)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Payment recorded",
+		"data":    paymentRecord,
+	})
+}
+
+func manualPaymentError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"success": false,
+			"error":   fiberErr.Message,
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order or share not found",
+		})
+	case errors.Is(err, billing.ErrShareNotPayable):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Share is already paid or being paid",
+		})
+	case errors.Is(err, payment.ErrGatewayPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "The customer has an online payment in progress for this order",
+		})
+	case errors.Is(err, payment.ErrUnknownPaymentMethod):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Payment method must be cash or edc",
+		})
+	case errors.Is(err, payment.ErrInsufficientTender):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Tendered amount is less than the amount due",
+		})
+	case errors.Is(err, payment.ErrApprovalCodeRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Approval code is required for EDC payments",
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to record payment",
+	})
+}
@@ -141,6 +141,15 @@ func (h *Handlers) CreateCategory(c *fiber.Ctx) error {
 		})
 	}
 
+	if category.Station == "" {
+		category.Station = models.StationKitchen
+	} else if !isValidStation(category.Station) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid station",
+		})
+	}
+
 	if err := h.DB.Create(&category).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -172,12 +181,25 @@ func (h *Handlers) UpdateCategory(c *fiber.Ctx) error {
 		})
 	}
 
-	result := h.DB.Model(&models.MenuCategory{}).Where("id = ?", id).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"name":          category.Name,
 		"description":   category.Description,
 		"display_order": category.DisplayOrder,
 		"is_active":     category.IsActive,
-	})
+		"tax_exempt":    category.TaxExempt,
+	}
+
+	if category.Station != "" {
+		if !isValidStation(category.Station) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid station",
+			})
+		}
+		updates["station"] = category.Station
+	}
+
+	result := h.DB.Model(&models.MenuCategory{}).Where("id = ?", id).Updates(updates)
 
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -215,6 +237,13 @@ func (h *Handlers) CreateMenuItem(c *fiber.Ctx) error {
 		})
 	}
 
+	if item.Station != nil && *item.Station != "" && !isValidStation(*item.Station) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid station",
+		})
+	}
+
 	if err := h.DB.Create(&item).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -246,6 +275,13 @@ func (h *Handlers) UpdateMenuItem(c *fiber.Ctx) error {
 		})
 	}
 
+	if item.Station != nil && *item.Station != "" && !isValidStation(*item.Station) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid station",
+		})
+	}
+
 	result := h.DB.Model(&models.MenuItem{}).Where("id = ?", id).Updates(item)
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -1,18 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"lendral3n/ordering-system/internal/middleware"
 	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
+	"lendral3n/ordering-system/internal/services/orderstatus"
+	"lendral3n/ordering-system/internal/services/pricing"
 	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CreateOrderRequest struct {
 	SessionToken string                   `json:"session_token"`
 	Items        []CreateOrderItemRequest `json:"items" validate:"required,min=1"`
+	OrderType    string                   `json:"order_type"` // dine_in (default) or takeaway
 	Notes        string                   `json:"notes"`
 }
 
@@ -57,11 +64,18 @@ func (h *Handlers) CreateOrder(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.OrderType == "" {
+		req.OrderType = models.OrderTypeDineIn
+	} else if req.OrderType != models.OrderTypeDineIn && req.OrderType != models.OrderTypeTakeaway {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order type must be dine_in or takeaway",
+		})
+	}
+
 	// Create order in transaction
 	var order models.Order
 	err := h.DB.Transaction(func(tx *gorm.DB) error {
-		// Calculate totals
-		var totalAmount float64
 		orderItems := make([]models.OrderItem, 0, len(req.Items))
 
 		for _, item := range req.Items {
@@ -71,7 +85,7 @@ func (h *Handlers) CreateOrder(c *fiber.Ctx) error {
 
 			// Get menu item
 			var menuItem models.MenuItem
-			if err := tx.First(&menuItem, item.MenuItemID).Error; err != nil {
+			if err := tx.Preload("Category").First(&menuItem, item.MenuItemID).Error; err != nil {
 				return fiber.NewError(fiber.StatusBadRequest, "Menu item not found")
 			}
 
@@ -84,23 +98,22 @@ func (h *Handlers) CreateOrder(c *fiber.Ctx) error {
 				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Insufficient stock for '%s'", menuItem.Name))
 			}
 
-			subtotal := menuItem.Price * float64(item.Quantity)
-			totalAmount += subtotal
-
 			orderItems = append(orderItems, models.OrderItem{
 				MenuItemID: uint(item.MenuItemID),
 				Quantity:   item.Quantity,
 				UnitPrice:  menuItem.Price,
-				Subtotal:   subtotal,
+				Subtotal:   menuItem.Price.Times(item.Quantity),
+				TaxExempt:  menuItem.IsTaxExempt(),
 				Notes:      &item.Notes,
 				Status:     models.OrderItemStatusPending,
 			})
 		}
 
-		// Calculate tax and service charge
-		taxAmount := totalAmount * h.Config.TaxPercentage / 100
-		serviceCharge := totalAmount * h.Config.ServicePercentage / 100
-		grandTotal := totalAmount + taxAmount + serviceCharge
+		// Price the order under the tax and service rules in effect now
+		rules, err := pricing.Rules(tx, time.Now(), h.Config.TaxPercentage, h.Config.ServicePercentage)
+		if err != nil {
+			return err
+		}
 
 		// Create order
 		order = models.Order{
@@ -108,19 +121,21 @@ func (h *Handlers) CreateOrder(c *fiber.Ctx) error {
 			SessionID:     session.ID,
 			TableID:       session.TableID,
 			Status:        models.OrderStatusPending,
-			TotalAmount:   totalAmount,
-			TaxAmount:     taxAmount,
-			ServiceCharge: serviceCharge,
-			GrandTotal:    grandTotal,
+			OrderType:     req.OrderType,
 			PaymentStatus: models.PaymentStatusUnpaid,
 			Notes:         &req.Notes,
 			OrderItems:    orderItems,
 		}
+		pricing.Apply(&order, rules)
 
 		if err := tx.Create(&order).Error; err != nil {
 			return err
 		}
 
+		if err := orderstatus.RecordCreated(tx, &order, orderstatus.Change{}); err != nil {
+			return err
+		}
+
 		// Update inventory for tracked items
 		for _, item := range orderItems {
 			var menuItem models.MenuItem
@@ -169,10 +184,12 @@ func (h *Handlers) CreateOrder(c *fiber.Ctx) error {
 	}
 
 	// Load relations
-	h.DB.Preload("OrderItems.MenuItem").Preload("Table").First(&order, order.ID)
+	h.DB.Preload("OrderItems.MenuItem").Preload("Table").Preload("Charges").First(&order, order.ID)
 
 	// Send notification to staff
 	go h.NotificationHub.BroadcastNewOrder(&order)
+	go h.printKitchenTickets(order.ID)
+	go h.broadcastOrderStations(order.ID)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -200,7 +217,7 @@ func (h *Handlers) GetOrder(c *fiber.Ctx) error {
 	}
 
 	var order models.Order
-	if err := h.DB.Preload("OrderItems.MenuItem").Preload("Table").Preload("Payment").First(&order, orderID).Error; err != nil {
+	if err := h.DB.Preload("OrderItems.MenuItem").Preload("Table").Preload("Charges").Preload("Payment").First(&order, orderID).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"error":   "Order not found",
@@ -348,6 +365,39 @@ func (h *Handlers) GetOrders(c *fiber.Ctx) error {
 	})
 }
 
+// GetStaffOrder returns an order with its status timeline.
+func (h *Handlers) GetStaffOrder(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	var order models.Order
+	if err := h.DB.Preload("OrderItems.MenuItem").Preload("Table").Preload("Charges").Preload("Payment").First(&order, orderID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order not found",
+		})
+	}
+
+	order.Timeline, err = orderstatus.Timeline(h.DB, order.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get order timeline",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Order retrieved",
+		"data":    order,
+	})
+}
+
 func (h *Handlers) UpdateOrderStatus(c *fiber.Ctx) error {
 	orderID, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
@@ -359,6 +409,7 @@ func (h *Handlers) UpdateOrderStatus(c *fiber.Ctx) error {
 
 	var req struct {
 		Status string `json:"status" validate:"required"`
+		Reason string `json:"reason"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -394,38 +445,27 @@ func (h *Handlers) UpdateOrderStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update status
-	result := h.DB.Model(&models.Order{}).Where("id = ?", orderID).Update("status", req.Status)
-	if result.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   "Failed to update order status",
-		})
-	}
-
-	if result.RowsAffected == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error":   "Order not found",
-		})
+	// Cancellation has its own flow that returns stock and refunds
+	if req.Status == models.OrderStatusCancelled {
+		return h.cancelStaffOrder(c, uint(orderID), req.Reason)
 	}
 
-	// Get order for notification
+	// Update status through the state machine, moving the items along
 	var order models.Order
-	h.DB.Preload("Table").First(&order, orderID)
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return err
+		}
+		return orderstatus.TransitionOrderWithItems(tx, &order, req.Status, staffChange(c, req.Reason))
+	})
+
+	if err != nil {
+		return orderStatusError(c, err, "Order not found", "Failed to update order status")
+	}
 
 	// Send notification
 	go h.NotificationHub.BroadcastOrderStatusUpdate(&order)
-
-	// If order is ready, create notification
-	if req.Status == models.OrderStatusReady {
-		notification := models.Notification{
-			OrderID: &[]uint{uint(orderID)}[0],
-			Type:    models.NotificationOrderReady,
-			Message: fmt.Sprintf("Order #%s is ready to serve", order.OrderNumber),
-		}
-		h.DB.Create(&notification)
-	}
+	go h.broadcastOrderStations(order.ID)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -444,6 +484,7 @@ func (h *Handlers) UpdateOrderItemStatus(c *fiber.Ctx) error {
 
 	var req struct {
 		Status string `json:"status" validate:"required"`
+		Reason string `json:"reason"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -477,25 +518,86 @@ func (h *Handlers) UpdateOrderItemStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update status
-	result := h.DB.Model(&models.OrderItem{}).Where("id = ?", itemID).Update("status", req.Status)
-	if result.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	// Update status and roll it up to the order in one transaction
+	var order, cancelledOrder *models.Order
+	var orderChanged bool
+	change := staffChange(c, req.Reason)
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var item models.OrderItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, itemID).Error; err != nil {
+			return err
+		}
+
+		// A cancelled item comes off the bill and returns its stock; the
+		// last one cancels the order
+		if req.Status == models.OrderItemStatusCancelled {
+			if cancelledOrder, err = orderstatus.CancelItem(tx, &item, change); err != nil {
+				return err
+			}
+			if cancelledOrder != nil {
+				if err := billing.VoidSplit(tx, item.OrderID); err != nil {
+					return err
+				}
+			}
+		} else if err := orderstatus.TransitionItem(tx, &item, req.Status, change); err != nil {
+			return err
+		}
+
+		order, orderChanged, err = orderstatus.Sync(tx, item.OrderID, change)
+		return err
+	})
+
+	if err != nil {
+		return orderStatusError(c, err, "Order item not found", "Failed to update item status")
+	}
+
+	go h.broadcastItemStation(uint(itemID))
+	if orderChanged || cancelledOrder != nil {
+		go h.NotificationHub.BroadcastOrderStatusUpdate(order)
+	}
+
+	if cancelledOrder != nil {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Item status updated, order cancelled",
+			"data":    h.settleCancelledOrder(cancelledOrder, change),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Item status updated",
+	})
+}
+
+// staffChange attributes a status change to the authenticated staff member.
+func staffChange(c *fiber.Ctx, reason string) orderstatus.Change {
+	change := orderstatus.Change{Reason: reason}
+	if staff := middleware.CurrentStaff(c); staff != nil {
+		change.StaffID = &staff.ID
+	}
+	return change
+}
+
+func orderStatusError(c *fiber.Ctx, err error, notFound, failed string) error {
+	var transitionErr *orderstatus.TransitionError
+	if errors.As(err, &transitionErr) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"success": false,
-			"error":   "Failed to update item status",
+			"error":   transitionErr.Error(),
 		})
 	}
 
-	if result.RowsAffected == 0 {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
-			"error":   "Order item not found",
+			"error":   notFound,
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Item status updated",
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   failed,
 	})
 }
 
@@ -3,7 +3,10 @@ package handlers
 import (
 	"fmt"
 	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
 	"lendral3n/ordering-system/internal/services/payment"
+	"lendral3n/ordering-system/internal/services/qrcode"
+	"log"
 	"strconv"
 	"time"
 
@@ -11,13 +14,25 @@ import (
 )
 
 type CreatePaymentRequest struct {
-	OrderID       int    `json:"order_id" validate:"required"`
-	CustomerEmail string `json:"customer_email,omitempty"`
+	OrderID       int          `json:"order_id" validate:"required"`
+	CustomerEmail string       `json:"customer_email,omitempty"`
+	PaymentType   string       `json:"payment_type,omitempty"` // "qris" for a QR code on the bill instead of the payment page
+	TipPercent    float64      `json:"tip_percent,omitempty"`  // one of the tip presets
+	TipAmount     models.Money `json:"tip_amount,omitempty"`   // custom tip
 }
 
 type CreatePaymentResponse struct {
-	Token       string `json:"token"`
-	RedirectURL string `json:"redirect_url"`
+	Token       string               `json:"token"`
+	RedirectURL string               `json:"redirect_url"`
+	QRIS        *QRISPaymentResponse `json:"qris,omitempty"`
+}
+
+// QRISPaymentResponse is a QRIS code for the customer to scan from the bill.
+type QRISPaymentResponse struct {
+	QRString  string       `json:"qr_string"`
+	QRImage   string       `json:"qr_image"` // PNG data URI
+	Amount    models.Money `json:"amount"`
+	ExpiresAt time.Time    `json:"expires_at"`
 }
 
 func (h *Handlers) CreatePayment(c *fiber.Ctx) error {
@@ -56,25 +71,62 @@ func (h *Handlers) CreatePayment(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if order is already paid
-	if order.PaymentStatus == models.PaymentStatusPaid {
+	if order.Status == models.OrderStatusCancelled {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order is cancelled",
+		})
+	}
+
+	// Check if order is already being paid, paid or refunded
+	switch order.PaymentStatus {
+	case models.PaymentStatusPending:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Payment already in progress",
+		})
+	case models.PaymentStatusPaid, models.PaymentStatusRefunded, models.PaymentStatusPartiallyRefunded:
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error":   "Order already paid",
 		})
 	}
 
-	// Check if payment already exists
-	var existingPayment models.Payment
-	err := h.DB.Where("order_id = ?", order.ID).First(&existingPayment).Error
-	if err == nil && existingPayment.TransactionStatus != nil {
-		status := *existingPayment.TransactionStatus
-		if status == "pending" || status == "settlement" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Payment already in progress",
-			})
-		}
+	// Orders being paid in shares are paid per share
+	if split, _ := billing.HasOpenSplit(h.DB, order.ID); split {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order is being paid in shares",
+		})
+	}
+
+	// Check if a payment is already open or settled
+	var existingPayments int64
+	if err := h.DB.Model(&models.Payment{}).
+		Where("order_id = ? AND status IN ?", order.ID, []string{models.PaymentStatusPending, models.PaymentStatusPaid}).
+		Count(&existingPayments).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to check existing payments",
+		})
+	}
+	if existingPayments > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Payment already in progress",
+		})
+	}
+
+	tip, err := billing.TipRequest{
+		Percent: req.TipPercent,
+		Amount:  req.TipAmount,
+		Presets: h.Config.TipPresets,
+	}.Resolve(order.TotalAmount)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
 	}
 
 	// Get customer info from session
@@ -87,29 +139,54 @@ func (h *Handlers) CreatePayment(c *fiber.Ctx) error {
 		customerPhone = *session.CustomerPhone
 	}
 
-	// Create Midtrans transaction
+	// Each attempt needs its own reference, the gateway refuses one it has
+	// already seen, e.g. when an expired QRIS code is replaced
+	reference := fmt.Sprintf("%s-%d", order.OrderNumber, time.Now().UnixNano())
+
+	// Create gateway transaction
 	transReq := payment.CreateTransactionRequest{
-		Order:         &order,
+		OrderID:       reference,
+		Amount:        order.GrandTotal + tip,
+		Items:         payment.WithTip(payment.OrderLineItems(&order), tip),
 		CustomerName:  customerName,
 		CustomerPhone: customerPhone,
 		CustomerEmail: req.CustomerEmail,
 	}
 
-	transResp, err := h.MidtransService.CreateTransaction(transReq)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   "Failed to create payment",
-		})
-	}
-
 	// Create payment record
 	paymentRecord := models.Payment{
-		OrderID:           order.ID,
-		MidtransOrderID:   order.OrderNumber,
-		TransactionStatus: &[]string{"pending"}[0],
-		GrossAmount:       order.GrandTotal,
-		Currency:          "IDR",
+		OrderID:         order.ID,
+		Provider:        h.PaymentProvider.Name(),
+		Method:          models.PaymentMethodGateway,
+		Status:          models.PaymentStatusPending,
+		MidtransOrderID: reference,
+		GrossAmount:     order.GrandTotal + tip,
+		TipAmount:       tip,
+		Currency:        "IDR",
+	}
+
+	var response CreatePaymentResponse
+	if req.PaymentType == payment.PaymentTypeQRIS {
+		charge, qris, err := h.chargeQRIS(transReq)
+		if err != nil {
+			log.Printf("Failed to create QRIS charge for order %s: %v", order.OrderNumber, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to create payment",
+			})
+		}
+		setQRIS(&paymentRecord, charge)
+		response.QRIS = qris
+	} else {
+		transResp, err := h.PaymentProvider.CreateTransaction(transReq)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to create payment",
+			})
+		}
+		response.Token = transResp.Token
+		response.RedirectURL = transResp.RedirectURL
 	}
 
 	if err := h.DB.Create(&paymentRecord).Error; err != nil {
@@ -122,11 +199,6 @@ func (h *Handlers) CreatePayment(c *fiber.Ctx) error {
 	// Update order payment status
 	h.DB.Model(&order).Update("payment_status", models.PaymentStatusPending)
 
-	response := CreatePaymentResponse{
-		Token:       transResp.Token,
-		RedirectURL: transResp.RedirectURL,
-	}
-
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Payment created",
@@ -134,6 +206,55 @@ func (h *Handlers) CreatePayment(c *fiber.Ctx) error {
 	})
 }
 
+// GetTipPresets lists the tip percentages offered at checkout.
+func (h *Handlers) GetTipPresets(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tip presets retrieved",
+		"data":    h.Config.TipPresets,
+	})
+}
+
+// chargeQRIS creates a QRIS charge and checks that the code carries the
+// amount being charged before it is shown to the customer.
+func (h *Handlers) chargeQRIS(req payment.CreateTransactionRequest) (*payment.QRISCharge, *QRISPaymentResponse, error) {
+	charge, err := h.PaymentProvider.CreateQRISCharge(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qris, err := qrcode.ParseQRIS(charge.QRString)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !qris.Dynamic || models.NewMoney(qris.Amount) != req.Amount {
+		return nil, nil, fmt.Errorf("QRIS code for %s does not carry the amount %d", req.OrderID, req.Amount)
+	}
+
+	image, err := qrcode.QRISDataURI(charge.QRString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return charge, &QRISPaymentResponse{
+		QRString:  charge.QRString,
+		QRImage:   image,
+		Amount:    req.Amount,
+		ExpiresAt: charge.ExpiresAt,
+	}, nil
+}
+
+// setQRIS stores a QRIS charge on its payment so the bill can show it again.
+func setQRIS(paymentRecord *models.Payment, charge *payment.QRISCharge) {
+	paymentType := payment.PaymentTypeQRIS
+	paymentRecord.PaymentType = &paymentType
+	paymentRecord.QRString = &charge.QRString
+	paymentRecord.ExpiresAt = &charge.ExpiresAt
+	if charge.TransactionID != "" {
+		paymentRecord.MidtransTransactionID = &charge.TransactionID
+	}
+}
+
 func (h *Handlers) GetPaymentStatus(c *fiber.Ctx) error {
 	// Validate session
 	sessionToken := c.Get("X-Session-Token")
@@ -171,49 +292,28 @@ func (h *Handlers) GetPaymentStatus(c *fiber.Ctx) error {
 	}
 
 	// Get payment
-	var payment models.Payment
-	if err := h.DB.Where("order_id = ?", orderID).First(&payment).Error; err != nil {
+	var paymentRecord models.Payment
+	if err := h.DB.Where("order_id = ?", orderID).Order("created_at DESC").First(&paymentRecord).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"error":   "Payment not found",
 		})
 	}
 
-	// Check transaction status from Midtrans
-	status, err := h.MidtransService.GetTransactionStatus(order.OrderNumber)
+	// Check transaction status with the gateway
+	var status *payment.TransactionStatus
+	if paymentRecord.Method == models.PaymentMethodGateway {
+		var reference string
+		if reference, err = payment.ProviderReference(h.DB, &paymentRecord); err == nil {
+			status, err = h.PaymentProvider.GetTransactionStatus(reference)
+		}
+	}
 	if err == nil && status != nil {
 		// Update payment record if status changed
-		if payment.TransactionStatus == nil || *payment.TransactionStatus != status.TransactionStatus {
-			payment.TransactionStatus = &status.TransactionStatus
-			payment.MidtransTransactionID = &status.TransactionID
-			payment.PaymentType = &status.PaymentType
-
-			if status.VaNumbers != nil && len(status.VaNumbers) > 0 {
-				payment.VANumber = &status.VaNumbers[0].VANumber
-				payment.Bank = &status.VaNumbers[0].Bank
-			}
-
-			h.DB.Save(&payment)
-
-			// Update order payment status
-			if status.TransactionStatus == "settlement" || status.TransactionStatus == "capture" {
-				h.DB.Model(&order).Updates(map[string]interface{}{
-					"payment_status": models.PaymentStatusPaid,
-					"payment_method": status.PaymentType,
-				})
-
-				// Create notification
-				notification := models.Notification{
-					OrderID: &order.ID,
-					Type:    models.NotificationPaymentReceived,
-					Message: fmt.Sprintf("Payment received for order #%s", order.OrderNumber),
-				}
-				h.DB.Create(&notification)
-
-				// Send notifications
-				go h.NotificationHub.BroadcastPaymentReceived(&payment, &order)
-			} else if status.TransactionStatus == "expire" || status.TransactionStatus == "cancel" {
-				h.DB.Model(&order).Update("payment_status", models.PaymentStatusFailed)
+		if paymentRecord.TransactionStatus == nil || *paymentRecord.TransactionStatus != status.ProviderStatus {
+			if err := h.ApplyTransactionStatus(status); err == nil {
+				h.DB.First(&order, order.ID)
+				h.DB.First(&paymentRecord, paymentRecord.ID)
 			}
 		}
 	}
@@ -221,11 +321,33 @@ func (h *Handlers) GetPaymentStatus(c *fiber.Ctx) error {
 	response := map[string]interface{}{
 		"order_id":           order.ID,
 		"payment_status":     order.PaymentStatus,
-		"transaction_status": payment.TransactionStatus,
-		"payment_type":       payment.PaymentType,
-		"va_number":          payment.VANumber,
-		"bank":               payment.Bank,
-		"gross_amount":       payment.GrossAmount,
+		"transaction_status": paymentRecord.TransactionStatus,
+		"payment_type":       paymentRecord.PaymentType,
+		"va_number":          paymentRecord.VANumber,
+		"bank":               paymentRecord.Bank,
+		"gross_amount":       paymentRecord.GrossAmount,
+	}
+
+	// Show an unpaid QRIS code again on the bill. A session bill code
+	// carries the whole bill, not this order's part of it.
+	if paymentRecord.Status == models.PaymentStatusPending && paymentRecord.QRString != nil &&
+		paymentRecord.ExpiresAt != nil && time.Now().Before(*paymentRecord.ExpiresAt) {
+		amount := paymentRecord.GrossAmount
+		var billErr error
+		if paymentRecord.SessionBillID != nil {
+			var bill models.SessionBill
+			billErr = h.DB.Select("total_amount").First(&bill, *paymentRecord.SessionBillID).Error
+			amount = bill.TotalAmount
+		}
+		image, err := qrcode.QRISDataURI(*paymentRecord.QRString)
+		if billErr == nil && err == nil {
+			response["qris"] = QRISPaymentResponse{
+				QRString:  *paymentRecord.QRString,
+				QRImage:   image,
+				Amount:    amount,
+				ExpiresAt: *paymentRecord.ExpiresAt,
+			}
+		}
 	}
 
 	return c.JSON(fiber.Map{
@@ -243,7 +365,7 @@ func (h *Handlers) HandlePaymentFinish(c *fiber.Ctx) error {
 
 	// Build redirect URL based on status
 	redirectURL := "/payment-success"
-	if transactionStatus == "deny" || transactionStatus == "expire" || transactionStatus == "cancel" {
+	if h.PaymentProvider.NormalizeStatus(transactionStatus) == models.PaymentStatusFailed {
 		redirectURL = "/payment-failed"
 	}
 
@@ -265,7 +387,7 @@ func (h *Handlers) GetPayments(c *fiber.Ctx) error {
 
 	// Apply filters
 	if status != "" {
-		query = query.Where("transaction_status = ?", status)
+		query = query.Where("status = ?", status)
 	}
 	if dateFrom != "" {
 		if t, err := time.Parse("2006-01-02", dateFrom); err == nil {
@@ -303,16 +425,31 @@ func (h *Handlers) VerifyPayment(c *fiber.Ctx) error {
 	}
 
 	// Get payment
-	var payment models.Payment
-	if err := h.DB.Preload("Order").First(&payment, paymentID).Error; err != nil {
+	var paymentRecord models.Payment
+	if err := h.DB.First(&paymentRecord, paymentID).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"error":   "Payment not found",
 		})
 	}
 
-	// Check transaction status from Midtrans
-	status, err := h.MidtransService.GetTransactionStatus(payment.MidtransOrderID)
+	// Manual payments are settled when recorded
+	if paymentRecord.Method != models.PaymentMethodGateway {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Only gateway payments can be verified",
+		})
+	}
+
+	// Check transaction status with the gateway
+	reference, err := payment.ProviderReference(h.DB, &paymentRecord)
+	if err == nil {
+		var status *payment.TransactionStatus
+		if status, err = h.PaymentProvider.GetTransactionStatus(reference); err == nil {
+			// Update payment and order, or every order of a session bill
+			err = h.ApplyTransactionStatus(status)
+		}
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -320,23 +457,11 @@ func (h *Handlers) VerifyPayment(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update payment record
-	payment.TransactionStatus = &status.TransactionStatus
-	payment.MidtransTransactionID = &status.TransactionID
-	payment.PaymentType = &status.PaymentType
-	h.DB.Save(&payment)
-
-	// Update order if payment successful
-	if status.TransactionStatus == "settlement" || status.TransactionStatus == "capture" {
-		h.DB.Model(&models.Order{}).Where("id = ?", payment.OrderID).Updates(map[string]interface{}{
-			"payment_status": models.PaymentStatusPaid,
-			"payment_method": status.PaymentType,
-		})
-	}
+	h.DB.Preload("Order").First(&paymentRecord, paymentRecord.ID)
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Payment verified",
-		"data":    payment,
+		"data":    paymentRecord,
 	})
 }
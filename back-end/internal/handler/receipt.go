@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/services/invoice"
+	"lendral3n/ordering-system/internal/services/printer"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type PrintReceiptRequest struct {
+	Paper string `json:"paper"` // 58 or 80, defaults to RECEIPT_PAPER_WIDTH
+}
+
+// Staff endpoints
+func (h *Handlers) GetOrderReceipt(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	paper, err := printer.ParsePaper(c.Query("paper"), printer.Paper(h.Config.ReceiptPaperWidth))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Paper must be 58 or 80",
+		})
+	}
+
+	receipt, err := h.InvoiceService.GenerateReceipt(uint(orderID), paper)
+	if err != nil {
+		return receiptError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="receipt-%d.bin"`, orderID))
+	return c.Send(receipt)
+}
+
+func (h *Handlers) PrintOrderReceipt(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	if h.ReceiptPrinter == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "Receipt printer is not configured",
+		})
+	}
+
+	var req PrintReceiptRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid request body",
+			})
+		}
+	}
+
+	paper, err := printer.ParsePaper(req.Paper, printer.Paper(h.Config.ReceiptPaperWidth))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Paper must be 58 or 80",
+		})
+	}
+
+	receipt, err := h.InvoiceService.GenerateReceipt(uint(orderID), paper)
+	if err != nil {
+		return receiptError(c, err)
+	}
+
+	if err := h.ReceiptPrinter.Print(fmt.Sprintf("receipt-%d", orderID), receipt); err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to print receipt",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Receipt sent to printer",
+	})
+}
+
+// autoPrintReceipt prints the receipt of a newly paid order when automatic
+// printing is enabled. Failures are logged; cashiers can reprint manually.
+func (h *Handlers) autoPrintReceipt(orderID uint) {
+	if !h.Config.ReceiptAutoPrint || h.ReceiptPrinter == nil {
+		return
+	}
+
+	receipt, err := h.InvoiceService.GenerateReceipt(orderID, printer.Paper(h.Config.ReceiptPaperWidth))
+	if err != nil {
+		log.Printf("Failed to generate receipt for order %d: %v", orderID, err)
+		return
+	}
+
+	if err := h.ReceiptPrinter.Print(fmt.Sprintf("receipt-%d", orderID), receipt); err != nil {
+		log.Printf("Failed to print receipt for order %d: %v", orderID, err)
+	}
+}
+
+func receiptError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, invoice.ErrOrderNotPaid) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Receipt is only available for paid orders",
+		})
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order not found",
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to generate receipt",
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func (h *Handlers) GetReconciliationReports(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 30)
+	offset := c.QueryInt("offset", 0)
+
+	var reports []models.ReconciliationReport
+	if err := h.DB.Order("date DESC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get reconciliation reports",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Reconciliation reports retrieved",
+		"data":    reports,
+	})
+}
+
+func (h *Handlers) GetReconciliationReport(c *fiber.Ctx) error {
+	reportID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid report ID",
+		})
+	}
+
+	var report models.ReconciliationReport
+	if err := h.DB.Preload("Mismatches").First(&report, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "Report not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get reconciliation report",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Reconciliation report retrieved",
+		"data":    report,
+	})
+}
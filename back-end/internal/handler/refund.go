@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/payment"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type RefundPaymentRequest struct {
+	Amount models.Money `json:"amount"` // 0 or omitted refunds the remaining balance
+	Reason string       `json:"reason"`
+}
+
+// Staff endpoints
+func (h *Handlers) RefundPayment(c *fiber.Ctx) error {
+	paymentID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid payment ID",
+		})
+	}
+
+	var req RefundPaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Refund reason is required",
+		})
+	}
+
+	change := staffChange(c, req.Reason)
+	refund, err := h.RefundService.Refund(uint(paymentID), req.Amount, change.Reason, change.StaffID)
+	if err != nil {
+		return refundError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Payment refunded",
+		"data":    refund,
+	})
+}
+
+func (h *Handlers) GetPaymentRefunds(c *fiber.Ctx) error {
+	paymentID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid payment ID",
+		})
+	}
+
+	refunds, err := h.RefundService.GetRefunds(uint(paymentID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get refunds",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Refunds retrieved",
+		"data":    refunds,
+	})
+}
+
+func refundError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Payment not found",
+		})
+	case errors.Is(err, payment.ErrPaymentNotRefundable):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Only settled payments can be refunded",
+		})
+	case errors.Is(err, payment.ErrInvalidRefundAmount):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Refund amount exceeds the refundable balance",
+		})
+	case errors.Is(err, payment.ErrRefundFailed):
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to refund payment",
+	})
+}
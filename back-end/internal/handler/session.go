@@ -10,7 +10,8 @@ import (
 )
 
 type StartSessionRequest struct {
-	TableNumber   string `json:"table_number" validate:"required"`
+	TableToken    string `json:"table_token" validate:"required"` // from the scanned QR code
+	TableNumber   string `json:"table_number"`
 	CustomerName  string `json:"customer_name"`
 	CustomerPhone string `json:"customer_phone"`
 }
@@ -29,9 +30,33 @@ func (h *Handlers) StartSession(c *fiber.Ctx) error {
 		})
 	}
 
+	// The table comes from the signed QR token, never from the client alone
+	tableNumber, err := h.QRService.VerifyTableToken(req.TableToken)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid table QR code",
+		})
+	}
+
+	if req.TableNumber != "" && req.TableNumber != tableNumber {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Table QR code does not match table",
+		})
+	}
+
 	// Get table by number
 	var table models.Table
-	if err := h.DB.Where("table_number = ?", req.TableNumber).First(&table).Error; err != nil {
+	if err := h.DB.Where("table_number = ?", tableNumber).First(&table).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Table not found",
+		})
+	}
+
+	// Retired tables cannot take new sessions
+	if !table.IsActive {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"error":   "Table not found",
@@ -48,7 +73,7 @@ func (h *Handlers) StartSession(c *fiber.Ctx) error {
 
 	// Check for existing active session
 	var existingSession models.CustomerSession
-	err := h.DB.Where("table_id = ? AND ended_at IS NULL", table.ID).First(&existingSession).Error
+	err = h.DB.Where("table_id = ? AND ended_at IS NULL", table.ID).First(&existingSession).Error
 	if err == nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"success": false,
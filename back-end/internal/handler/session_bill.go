@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
+	"lendral3n/ordering-system/internal/services/payment"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CreateSessionPaymentRequest struct {
+	CustomerEmail string       `json:"customer_email,omitempty"`
+	PaymentType   string       `json:"payment_type,omitempty"` // "qris" for a QR code on the bill instead of the payment page
+	TipPercent    float64      `json:"tip_percent,omitempty"`  // one of the tip presets
+	TipAmount     models.Money `json:"tip_amount,omitempty"`   // custom tip
+}
+
+type CreateSessionPaymentResponse struct {
+	Token       string               `json:"token"`
+	RedirectURL string               `json:"redirect_url"`
+	QRIS        *QRISPaymentResponse `json:"qris,omitempty"`
+	Bill        *models.SessionBill  `json:"bill"`
+}
+
+// GetSessionBill previews what a session checkout would charge.
+func (h *Handlers) GetSessionBill(c *fiber.Ctx) error {
+	// Validate session
+	sessionToken := c.Get("X-Session-Token")
+	if sessionToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session token required",
+		})
+	}
+
+	var session models.CustomerSession
+	if err := h.DB.Where("session_token = ?", sessionToken).First(&session).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid session",
+		})
+	}
+
+	orders, err := billing.UnpaidOrders(h.DB, session.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get unpaid orders",
+		})
+	}
+
+	var total models.Money
+	for _, order := range orders {
+		total += order.GrandTotal
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Session bill retrieved",
+		"data": fiber.Map{
+			"orders":       orders,
+			"total_amount": total,
+			"tip_presets":  h.Config.TipPresets,
+		},
+	})
+}
+
+// CreateSessionPayment pays every unpaid order of the session in one
+// gateway transaction.
+func (h *Handlers) CreateSessionPayment(c *fiber.Ctx) error {
+	// Validate session
+	sessionToken := c.Get("X-Session-Token")
+	if sessionToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session token required",
+		})
+	}
+
+	var req CreateSessionPaymentRequest
+	c.BodyParser(&req)
+
+	var session models.CustomerSession
+	if err := h.DB.Where("session_token = ?", sessionToken).First(&session).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid session",
+		})
+	}
+
+	var bill *models.SessionBill
+	var orders []models.Order
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		bill, orders, err = billing.CreateSessionBill(tx, session.ID, h.PaymentProvider.Name(), billing.TipRequest{
+			Percent: req.TipPercent,
+			Amount:  req.TipAmount,
+			Presets: h.Config.TipPresets,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, billing.ErrNothingToPay) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "No unpaid orders in this session",
+			})
+		}
+		if errors.Is(err, billing.ErrInvalidTip) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create payment",
+		})
+	}
+
+	// Get customer info from session
+	customerName := "Guest"
+	customerPhone := ""
+	if session.CustomerName != nil {
+		customerName = *session.CustomerName
+	}
+	if session.CustomerPhone != nil {
+		customerPhone = *session.CustomerPhone
+	}
+
+	transReq := payment.CreateTransactionRequest{
+		OrderID:       bill.Reference,
+		Amount:        bill.TotalAmount,
+		Items:         payment.WithTip(billing.SessionLineItems(orders), bill.TipAmount),
+		CustomerName:  customerName,
+		CustomerPhone: customerPhone,
+		CustomerEmail: req.CustomerEmail,
+	}
+
+	response := CreateSessionPaymentResponse{Bill: bill}
+	if req.PaymentType == payment.PaymentTypeQRIS {
+		var charge *payment.QRISCharge
+		charge, response.QRIS, err = h.chargeQRIS(transReq)
+		if err == nil {
+			// Every order on the bill shows the same code
+			for i := range bill.Payments {
+				setQRIS(&bill.Payments[i], charge)
+				if err = h.DB.Save(&bill.Payments[i]).Error; err != nil {
+					break
+				}
+			}
+		}
+	} else {
+		var transResp *payment.TransactionResponse
+		transResp, err = h.PaymentProvider.CreateTransaction(transReq)
+		if err == nil {
+			response.Token = transResp.Token
+			response.RedirectURL = transResp.RedirectURL
+		}
+	}
+	if err != nil {
+		// Release the orders so they can be paid again
+		h.DB.Transaction(func(tx *gorm.DB) error {
+			return billing.FailSessionBill(tx, bill.ID)
+		})
+		log.Printf("Failed to create payment for session bill %s: %v", bill.Reference, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create payment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Payment created",
+		"data":    response,
+	})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UpdateRestaurantProfileRequest struct {
+	Name               *string `json:"name"`
+	Address            *string `json:"address"`
+	Phone              *string `json:"phone"`
+	Email              *string `json:"email"`
+	TaxID              *string `json:"tax_id"`
+	LogoURL            *string `json:"logo_url"`
+	FooterText         *string `json:"footer_text"`
+	TaxLabel           *string `json:"tax_label"`
+	ServiceChargeLabel *string `json:"service_charge_label"`
+}
+
+// Staff endpoints
+func (h *Handlers) GetRestaurantProfile(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Restaurant profile retrieved",
+		"data":    h.InvoiceService.GetRestaurantProfile(),
+	})
+}
+
+func (h *Handlers) UpdateRestaurantProfile(c *fiber.Ctx) error {
+	var req UpdateRestaurantProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	profile := h.InvoiceService.GetRestaurantProfile()
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Restaurant name is required",
+			})
+		}
+		profile.Name = *req.Name
+	}
+	if req.Address != nil {
+		profile.Address = *req.Address
+	}
+	if req.Phone != nil {
+		profile.Phone = *req.Phone
+	}
+	if req.Email != nil {
+		profile.Email = *req.Email
+	}
+	if req.TaxID != nil {
+		profile.TaxID = *req.TaxID
+	}
+	if req.LogoURL != nil {
+		if *req.LogoURL == "" {
+			profile.LogoURL = nil
+		} else {
+			profile.LogoURL = req.LogoURL
+		}
+	}
+	if req.FooterText != nil {
+		profile.FooterText = *req.FooterText
+	}
+	if req.TaxLabel != nil && *req.TaxLabel != "" {
+		profile.TaxLabel = *req.TaxLabel
+	}
+	if req.ServiceChargeLabel != nil && *req.ServiceChargeLabel != "" {
+		profile.ServiceChargeLabel = *req.ServiceChargeLabel
+	}
+
+	if err := h.DB.Save(&profile).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update restaurant profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Restaurant profile updated",
+		"data":    profile,
+	})
+}
+
+func (h *Handlers) UploadRestaurantLogo(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "No file uploaded",
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to open file",
+		})
+	}
+	defer src.Close()
+
+	ctx := context.Background()
+	result, err := h.CloudinaryService.UploadFile(ctx, src, file)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to upload file",
+		})
+	}
+
+	profile := h.InvoiceService.GetRestaurantProfile()
+	profile.LogoURL = &result.SecureURL
+
+	if err := h.DB.Save(&profile).Error; err != nil {
+		h.CloudinaryService.DeleteFile(ctx, result.PublicID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update restaurant profile",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Logo uploaded",
+		"data":    profile,
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/middleware"
+	"lendral3n/ordering-system/internal/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// StartShift clocks the current staff member in.
+func (h *Handlers) StartShift(c *fiber.Ctx) error {
+	staff := middleware.CurrentStaff(c)
+
+	var shift models.StaffShift
+	err := h.DB.Where("staff_id = ? AND ended_at IS NULL", staff.ID).First(&shift).Error
+	if err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Shift already started",
+		})
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to start shift",
+		})
+	}
+
+	shift = models.StaffShift{
+		StaffID:   staff.ID,
+		StartedAt: time.Now(),
+	}
+	if err := h.DB.Create(&shift).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to start shift",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Shift started",
+		"data":    shift,
+	})
+}
+
+// EndShift clocks the current staff member out.
+func (h *Handlers) EndShift(c *fiber.Ctx) error {
+	staff := middleware.CurrentStaff(c)
+
+	var shift models.StaffShift
+	if err := h.DB.Where("staff_id = ? AND ended_at IS NULL", staff.ID).First(&shift).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "No open shift",
+		})
+	}
+
+	now := time.Now()
+	shift.EndedAt = &now
+	if err := h.DB.Model(&shift).Update("ended_at", now).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to end shift",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Shift ended",
+		"data":    shift,
+	})
+}
+
+func (h *Handlers) GetCurrentShift(c *fiber.Ctx) error {
+	staff := middleware.CurrentStaff(c)
+
+	var shift models.StaffShift
+	if err := h.DB.Where("staff_id = ? AND ended_at IS NULL", staff.ID).First(&shift).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "No open shift",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Shift retrieved",
+		"data":    shift,
+	})
+}
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
+	"lendral3n/ordering-system/internal/services/payment"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CreateSplitBillRequest struct {
+	Mode    string         `json:"mode" validate:"required"` // item, equal or custom
+	Shares  int            `json:"shares"`                   // equal: number of shares
+	Amounts []models.Money `json:"amounts"`                  // custom: amount per share
+	Items   [][]uint       `json:"items"`                    // item: order item IDs per share
+	Labels  []string       `json:"labels"`                   // optional share names, e.g. guest names
+}
+
+type PaySplitShareRequest struct {
+	CustomerEmail string `json:"customer_email,omitempty"`
+	PaymentType   string `json:"payment_type,omitempty"` // "qris" for a QR code on the bill instead of the payment page
+}
+
+func (h *Handlers) CreateCustomerSplitBill(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	if _, err := h.sessionOrder(c, uint(orderID)); err != nil {
+		return splitBillError(c, err)
+	}
+
+	return h.createSplitBill(c, uint(orderID))
+}
+
+func (h *Handlers) GetCustomerSplitBill(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	if _, err := h.sessionOrder(c, uint(orderID)); err != nil {
+		return splitBillError(c, err)
+	}
+
+	return h.getSplitBill(c, uint(orderID))
+}
+
+func (h *Handlers) CancelCustomerSplitBill(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	if _, err := h.sessionOrder(c, uint(orderID)); err != nil {
+		return splitBillError(c, err)
+	}
+
+	return h.cancelSplitBill(c, uint(orderID))
+}
+
+// PaySplitShare starts a gateway payment for one share of a split bill,
+// either on the payment page or as a QRIS code shown on the bill.
+func (h *Handlers) PaySplitShare(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	shareID, err := strconv.Atoi(c.Params("share_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid share ID",
+		})
+	}
+
+	var req PaySplitShareRequest
+	c.BodyParser(&req)
+
+	session, err := h.sessionOrder(c, uint(orderID))
+	if err != nil {
+		return splitBillError(c, err)
+	}
+
+	// Reserve the share before talking to the gateway
+	var order models.Order
+	var share *models.SplitShare
+	var paymentRecord models.Payment
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&order, orderID).Error; err != nil {
+			return err
+		}
+
+		var err error
+		share, err = billing.LockShare(tx, order.ID, uint(shareID))
+		if err != nil {
+			return err
+		}
+
+		paymentRecord = models.Payment{
+			OrderID:         order.ID,
+			SplitShareID:    &share.ID,
+			Provider:        h.PaymentProvider.Name(),
+			Method:          models.PaymentMethodGateway,
+			Status:          models.PaymentStatusPending,
+			MidtransOrderID: fmt.Sprintf("%s-S%d-%d", order.OrderNumber, share.ID, time.Now().UnixNano()),
+			GrossAmount:     share.Amount,
+			Currency:        "IDR",
+		}
+		if err := tx.Create(&paymentRecord).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(share).Updates(map[string]interface{}{
+			"status":     models.PaymentStatusPending,
+			"payment_id": paymentRecord.ID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&order).Update("payment_status", models.PaymentStatusPending).Error
+	})
+	if err != nil {
+		return splitBillError(c, err)
+	}
+
+	// Get customer info from session
+	customerName := "Guest"
+	customerPhone := ""
+	if session.CustomerName != nil {
+		customerName = *session.CustomerName
+	}
+	if session.CustomerPhone != nil {
+		customerPhone = *session.CustomerPhone
+	}
+
+	transReq := payment.CreateTransactionRequest{
+		OrderID:       paymentRecord.MidtransOrderID,
+		Amount:        share.Amount,
+		Items:         billing.ShareLineItems(&order, share),
+		CustomerName:  customerName,
+		CustomerPhone: customerPhone,
+		CustomerEmail: req.CustomerEmail,
+	}
+
+	var response CreatePaymentResponse
+	if req.PaymentType == payment.PaymentTypeQRIS {
+		var charge *payment.QRISCharge
+		if charge, response.QRIS, err = h.chargeQRIS(transReq); err == nil {
+			// The share is already reserved, so a failure here only stops
+			// the bill from showing the code again
+			setQRIS(&paymentRecord, charge)
+			if err := h.DB.Model(&paymentRecord).
+				Select("payment_type", "qr_string", "expires_at", "midtrans_transaction_id").
+				Updates(&paymentRecord).Error; err != nil {
+				log.Printf("Failed to save QRIS code for share %d of order %s: %v", share.ID, order.OrderNumber, err)
+			}
+		}
+	} else {
+		var transResp *payment.TransactionResponse
+		if transResp, err = h.PaymentProvider.CreateTransaction(transReq); err == nil {
+			response.Token = transResp.Token
+			response.RedirectURL = transResp.RedirectURL
+		}
+	}
+	if err != nil {
+		// Release the share so it can be paid again
+		h.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&paymentRecord).Update("status", models.PaymentStatusFailed).Error; err != nil {
+				return err
+			}
+			_, err := billing.UpdateShare(tx, share.ID, paymentRecord.ID, models.PaymentStatusFailed)
+			return err
+		})
+		log.Printf("Failed to create payment for share %d of order %s: %v", share.ID, order.OrderNumber, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create payment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Payment created",
+		"data":    response,
+	})
+}
+
+// Staff endpoints
+func (h *Handlers) CreateSplitBill(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	return h.createSplitBill(c, uint(orderID))
+}
+
+func (h *Handlers) GetSplitBill(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	return h.getSplitBill(c, uint(orderID))
+}
+
+func (h *Handlers) CancelSplitBill(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid order ID",
+		})
+	}
+
+	return h.cancelSplitBill(c, uint(orderID))
+}
+
+func (h *Handlers) createSplitBill(c *fiber.Ctx, orderID uint) error {
+	var req CreateSplitBillRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	var bill *models.SplitBill
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		bill, err = billing.CreateSplit(tx, orderID, billing.SplitRequest{
+			Mode:    req.Mode,
+			Shares:  req.Shares,
+			Amounts: req.Amounts,
+			Items:   req.Items,
+			Labels:  req.Labels,
+		})
+		return err
+	})
+	if err != nil {
+		return splitBillError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Bill split",
+		"data":    bill,
+	})
+}
+
+func (h *Handlers) getSplitBill(c *fiber.Ctx, orderID uint) error {
+	bill, err := billing.GetSplit(h.DB, orderID)
+	if err != nil {
+		return splitBillError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Split bill retrieved",
+		"data":    bill,
+	})
+}
+
+func (h *Handlers) cancelSplitBill(c *fiber.Ctx, orderID uint) error {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		return billing.CancelSplit(tx, orderID)
+	})
+	if err != nil {
+		return splitBillError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Split bill cancelled",
+	})
+}
+
+// sessionOrder checks that the order belongs to the caller's customer
+// session and returns the session.
+func (h *Handlers) sessionOrder(c *fiber.Ctx, orderID uint) (*models.CustomerSession, error) {
+	sessionToken := c.Get("X-Session-Token")
+	if sessionToken == "" {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Session token required")
+	}
+
+	var session models.CustomerSession
+	if err := h.DB.Where("session_token = ?", sessionToken).First(&session).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid session")
+	}
+
+	var order models.Order
+	if err := h.DB.Select("id", "session_id").First(&order, orderID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Order not found")
+	}
+	if order.SessionID != session.ID {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Unauthorized")
+	}
+
+	return &session, nil
+}
+
+func splitBillError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"success": false,
+			"error":   fiberErr.Message,
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order or share not found",
+		})
+	case errors.Is(err, billing.ErrNoSplit):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order has no split bill",
+		})
+	case errors.Is(err, billing.ErrSplitExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Order is already split",
+		})
+	case errors.Is(err, billing.ErrSplitInProgress):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Split bill already has payments",
+		})
+	case errors.Is(err, billing.ErrOrderNotSplitable):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Only unpaid orders can be split",
+		})
+	case errors.Is(err, billing.ErrShareNotPayable):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Share is already paid or being paid",
+		})
+	case errors.Is(err, billing.ErrInvalidSplit):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to process split bill",
+	})
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"lendral3n/ordering-system/internal/middleware"
+	"lendral3n/ordering-system/internal/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+type CreateStaffRequest struct {
+	Username string `json:"username" validate:"required"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	FullName string `json:"full_name" validate:"required"`
+	Role     string `json:"role" validate:"required"`
+}
+
+type UpdateStaffRequest struct {
+	Email    *string `json:"email"`
+	FullName *string `json:"full_name"`
+	Role     *string `json:"role"`
+}
+
+type ResetPasswordRequest struct {
+	// Optional - a temporary password is generated when empty
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// Admin endpoints
+func (h *Handlers) GetStaffList(c *fiber.Ctx) error {
+	activeOnly := c.Query("active_only") == "true"
+
+	staffList, err := h.StaffRepository.GetAll(activeOnly)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get staff",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Staff retrieved",
+		"data":    staffList,
+	})
+}
+
+func (h *Handlers) GetStaffMember(c *fiber.Ctx) error {
+	staff, err := h.findStaff(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Staff retrieved",
+		"data":    staff,
+	})
+}
+
+func (h *Handlers) CreateStaff(c *fiber.Ctx) error {
+	var req CreateStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if req.Username == "" || req.Email == "" || req.FullName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Username, email and full name are required",
+		})
+	}
+
+	if !isValidStaffRole(req.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid staff role",
+		})
+	}
+
+	if len(req.Password) < minPasswordLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Password must be at least 8 characters",
+		})
+	}
+
+	if _, err := h.StaffRepository.GetByUsername(req.Username); err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Username already exists",
+		})
+	}
+
+	if _, err := h.StaffRepository.GetByEmail(req.Email); err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Email already exists",
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to hash password",
+		})
+	}
+
+	// New accounts must pick their own password on first login
+	staff := models.Staff{
+		Username:           req.Username,
+		Email:              req.Email,
+		PasswordHash:       string(hashedPassword),
+		FullName:           req.FullName,
+		Role:               req.Role,
+		IsActive:           true,
+		MustChangePassword: true,
+	}
+
+	if err := h.StaffRepository.Create(&staff); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create staff",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Staff created",
+		"data":    staff,
+	})
+}
+
+func (h *Handlers) UpdateStaff(c *fiber.Ctx) error {
+	staff, err := h.findStaff(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if req.Email != nil && *req.Email != staff.Email {
+		if _, err := h.StaffRepository.GetByEmail(*req.Email); err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"error":   "Email already exists",
+			})
+		}
+		staff.Email = *req.Email
+	}
+
+	if req.FullName != nil && *req.FullName != "" {
+		staff.FullName = *req.FullName
+	}
+
+	if req.Role != nil {
+		if !isValidStaffRole(*req.Role) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid staff role",
+			})
+		}
+
+		// Prevent admins from locking themselves out
+		if staff.ID == middleware.CurrentStaff(c).ID && *req.Role != models.StaffRoleAdmin {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "You cannot change your own role",
+			})
+		}
+		staff.Role = *req.Role
+	}
+
+	if err := h.StaffRepository.Update(staff); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update staff",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Staff updated",
+		"data":    staff,
+	})
+}
+
+func (h *Handlers) ResetStaffPassword(c *fiber.Ctx) error {
+	staff, err := h.findStaff(c)
+	if err != nil {
+		return err
+	}
+
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	password := req.Password
+	if password == "" {
+		if password = generateTemporaryPassword(); password == "" {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to generate password",
+			})
+		}
+	} else if len(password) < minPasswordLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Password must be at least 8 characters",
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to hash password",
+		})
+	}
+
+	staff.PasswordHash = string(hashedPassword)
+	staff.MustChangePassword = true
+
+	if err := h.StaffRepository.Update(staff); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to reset password",
+		})
+	}
+
+	// The temporary password is only ever returned here
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Password reset",
+		"data": fiber.Map{
+			"staff_id":           staff.ID,
+			"temporary_password": password,
+		},
+	})
+}
+
+func (h *Handlers) DeactivateStaff(c *fiber.Ctx) error {
+	return h.setStaffActive(c, false)
+}
+
+func (h *Handlers) ActivateStaff(c *fiber.Ctx) error {
+	return h.setStaffActive(c, true)
+}
+
+// Authenticated staff endpoints
+func (h *Handlers) ChangePassword(c *fiber.Ctx) error {
+	staff := middleware.CurrentStaff(c)
+
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(staff.PasswordHash), []byte(req.CurrentPassword)) != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Current password is incorrect",
+		})
+	}
+
+	if len(req.NewPassword) < minPasswordLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Password must be at least 8 characters",
+		})
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "New password must be different",
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to hash password",
+		})
+	}
+
+	staff.PasswordHash = string(hashedPassword)
+	staff.MustChangePassword = false
+
+	if err := h.StaffRepository.Update(staff); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to change password",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Password changed",
+	})
+}
+
+func (h *Handlers) setStaffActive(c *fiber.Ctx, active bool) error {
+	staff, err := h.findStaff(c)
+	if err != nil {
+		return err
+	}
+
+	if !active && staff.ID == middleware.CurrentStaff(c).ID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "You cannot deactivate your own account",
+		})
+	}
+
+	staff.IsActive = active
+	if err := h.StaffRepository.Update(staff); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update staff",
+		})
+	}
+
+	message := "Staff activated"
+	if !active {
+		message = "Staff deactivated"
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    staff,
+	})
+}
+
+// findStaff loads the staff member from the :id param. Errors are returned
+// as *fiber.Error so the app error handler renders them.
+func (h *Handlers) findStaff(c *fiber.Ctx) (*models.Staff, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid staff ID")
+	}
+
+	staff, err := h.StaffRepository.GetByID(uint(id))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Staff not found")
+	}
+
+	return staff, nil
+}
+
+func isValidStaffRole(role string) bool {
+	validRoles := []string{
+		models.StaffRoleAdmin,
+		models.StaffRoleCashier,
+		models.StaffRoleWaiter,
+		models.StaffRoleKitchen,
+	}
+
+	for _, r := range validRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+func generateTemporaryPassword() string {
+	bytes := make([]byte, 9)
+	if _, err := rand.Read(bytes); err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/qrcode"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CreateTableRequest struct {
+	TableNumber string `json:"table_number" validate:"required"`
+	Capacity    int    `json:"capacity" validate:"required,min=1"`
+}
+
+type UpdateTableRequest struct {
+	TableNumber *string `json:"table_number"`
+	Capacity    *int    `json:"capacity"`
+	Status      *string `json:"status"`
+}
+
+// Staff endpoints
+func (h *Handlers) GetTables(c *fiber.Ctx) error {
+	activeOnly := c.Query("active_only") == "true"
+
+	tables, err := h.TableRepository.GetAll(activeOnly)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get tables",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tables retrieved",
+		"data":    tables,
+	})
+}
+
+func (h *Handlers) GetTable(c *fiber.Ctx) error {
+	table, err := h.findTable(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Table retrieved",
+		"data":    table,
+	})
+}
+
+func (h *Handlers) CreateTable(c *fiber.Ctx) error {
+	var req CreateTableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if req.TableNumber == "" || req.Capacity <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid table data",
+		})
+	}
+
+	if _, err := h.TableRepository.GetByTableNumber(req.TableNumber); err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Table number already exists",
+		})
+	}
+
+	qrCode, err := h.QRService.GenerateTableQRCodeBase64(req.TableNumber)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to generate QR code",
+		})
+	}
+
+	table := models.Table{
+		TableNumber: req.TableNumber,
+		QRCode:      qrCode,
+		Status:      models.TableStatusAvailable,
+		Capacity:    req.Capacity,
+		IsActive:    true,
+	}
+
+	if err := h.TableRepository.Create(&table); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create table",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Table created",
+		"data":    table,
+	})
+}
+
+func (h *Handlers) UpdateTable(c *fiber.Ctx) error {
+	table, err := h.findTable(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateTableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if req.TableNumber != nil && *req.TableNumber != "" && *req.TableNumber != table.TableNumber {
+		if _, err := h.TableRepository.GetByTableNumber(*req.TableNumber); err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"error":   "Table number already exists",
+			})
+		}
+
+		// The QR code encodes the table number, so it has to follow the rename
+		qrCode, err := h.QRService.GenerateTableQRCodeBase64(*req.TableNumber)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to generate QR code",
+			})
+		}
+		table.TableNumber = *req.TableNumber
+		table.QRCode = qrCode
+	}
+
+	if req.Capacity != nil {
+		if *req.Capacity <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid table capacity",
+			})
+		}
+		table.Capacity = *req.Capacity
+	}
+
+	if req.Status != nil {
+		if !isValidTableStatus(*req.Status) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid table status",
+			})
+		}
+		table.Status = *req.Status
+	}
+
+	if err := h.TableRepository.Update(table); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update table",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Table updated",
+		"data":    table,
+	})
+}
+
+func (h *Handlers) RetireTable(c *fiber.Ctx) error {
+	table, err := h.findTable(c)
+	if err != nil {
+		return err
+	}
+
+	// Don't pull a table out from under seated customers
+	var activeSessions int64
+	h.DB.Model(&models.CustomerSession{}).
+		Where("table_id = ? AND ended_at IS NULL", table.ID).
+		Count(&activeSessions)
+	if activeSessions > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "Table has an active session",
+		})
+	}
+
+	table.IsActive = false
+	if err := h.TableRepository.Update(table); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to retire table",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Table retired",
+		"data":    table,
+	})
+}
+
+func (h *Handlers) ReactivateTable(c *fiber.Ctx) error {
+	table, err := h.findTable(c)
+	if err != nil {
+		return err
+	}
+
+	table.IsActive = true
+	table.Status = models.TableStatusAvailable
+	if err := h.TableRepository.Update(table); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to reactivate table",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Table reactivated",
+		"data":    table,
+	})
+}
+
+// GetTableQRCode returns the table's QR code as a PNG download, or as a
+// base64 data URL when format=base64.
+func (h *Handlers) GetTableQRCode(c *fiber.Ctx) error {
+	table, err := h.findTable(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Query("format", "png") == "base64" {
+		qrCode, err := h.QRService.GenerateTableQRCodeBase64(table.TableNumber)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to generate QR code",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "QR code generated",
+			"data": fiber.Map{
+				"table_id":     table.ID,
+				"table_number": table.TableNumber,
+				"qr_code":      qrCode,
+			},
+		})
+	}
+
+	png, err := h.QRService.GenerateTableQRCode(table.TableNumber)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to generate QR code",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="table-%s-qr.png"`, table.TableNumber))
+	return c.Send(png)
+}
+
+// GetTableQRSheet renders every active table's QR code onto printable A4
+// pages. The grid can be tuned with the cols and rows query params.
+func (h *Handlers) GetTableQRSheet(c *fiber.Ctx) error {
+	layout := qrcode.DefaultSheetLayout()
+	layout.Columns = c.QueryInt("cols", layout.Columns)
+	layout.Rows = c.QueryInt("rows", layout.Rows)
+
+	if layout.Columns < 1 || layout.Columns > 6 || layout.Rows < 1 || layout.Rows > 8 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Grid must be between 1x1 and 6x8",
+		})
+	}
+
+	tables, err := h.TableRepository.GetAll(true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get tables",
+		})
+	}
+
+	if len(tables) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "No active tables",
+		})
+	}
+
+	sheet, err := h.QRService.GenerateTableSheetPDF(tables, layout)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to generate QR sheet",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="table-qr-codes.pdf"`)
+	return c.Send(sheet)
+}
+
+// RegenerateTableQRCodes re-encodes every table's stored QR code with the
+// current BASE_URL.
+func (h *Handlers) RegenerateTableQRCodes(c *fiber.Ctx) error {
+	tables, err := h.TableRepository.GetAll(false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get tables",
+		})
+	}
+
+	updated := 0
+	failed := make([]string, 0)
+	for i := range tables {
+		qrCode, err := h.QRService.GenerateTableQRCodeBase64(tables[i].TableNumber)
+		if err != nil {
+			failed = append(failed, tables[i].TableNumber)
+			continue
+		}
+
+		tables[i].QRCode = qrCode
+		if err := h.TableRepository.Update(&tables[i]); err != nil {
+			failed = append(failed, tables[i].TableNumber)
+			continue
+		}
+		updated++
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "QR codes regenerated",
+		"data": fiber.Map{
+			"updated": updated,
+			"failed":  failed,
+		},
+	})
+}
+
+// findTable loads the table from the :id param. Errors are returned as
+// *fiber.Error so the app error handler renders them.
+func (h *Handlers) findTable(c *fiber.Ctx) (*models.Table, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid table ID")
+	}
+
+	table, err := h.TableRepository.GetByID(uint(id))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Table not found")
+	}
+
+	return table, nil
+}
+
+func isValidTableStatus(status string) bool {
+	validStatuses := []string{
+		models.TableStatusAvailable,
+		models.TableStatusOccupied,
+		models.TableStatusReserved,
+	}
+
+	for _, s := range validStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TaxRuleRequest creates or updates a tax rule. Omitted fields keep their
+// current value on update.
+type TaxRuleRequest struct {
+	Name          *string    `json:"name"`
+	Kind          *string    `json:"kind"`     // tax or service
+	TaxType       *string    `json:"tax_type"` // pb1 or ppn, tax rules only
+	Rate          *float64   `json:"rate"`     // percentage
+	Inclusive     *bool      `json:"inclusive"`
+	DineInOnly    *bool      `json:"dine_in_only"`
+	EffectiveFrom *time.Time `json:"effective_from"` // defaults to now
+	EffectiveTo   *time.Time `json:"effective_to"`
+	IsActive      *bool      `json:"is_active"`
+}
+
+// Staff endpoints
+func (h *Handlers) GetTaxRules(c *fiber.Ctx) error {
+	var rules []models.TaxRule
+	if err := h.DB.Order("effective_from DESC, id").Find(&rules).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to get tax rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tax rules retrieved",
+		"data":    rules,
+	})
+}
+
+func (h *Handlers) CreateTaxRule(c *fiber.Ctx) error {
+	var req TaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	rule := models.TaxRule{EffectiveFrom: time.Now(), IsActive: true}
+	req.apply(&rule)
+	if msg := validateTaxRule(&rule); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   msg,
+		})
+	}
+
+	if err := h.DB.Create(&rule).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create tax rule",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tax rule created",
+		"data":    rule,
+	})
+}
+
+func (h *Handlers) UpdateTaxRule(c *fiber.Ctx) error {
+	rule, err := h.findTaxRule(c)
+	if err != nil {
+		return err
+	}
+
+	var req TaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	req.apply(rule)
+	if msg := validateTaxRule(rule); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   msg,
+		})
+	}
+
+	// Orders already placed keep the charges they were priced with
+	if err := h.DB.Save(rule).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to update tax rule",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tax rule updated",
+		"data":    rule,
+	})
+}
+
+func (h *Handlers) DeleteTaxRule(c *fiber.Ctx) error {
+	rule, err := h.findTaxRule(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.DB.Delete(rule).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to delete tax rule",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Tax rule deleted",
+	})
+}
+
+// findTaxRule loads the tax rule from the :id param. Errors are returned as
+// *fiber.Error so the app error handler renders them.
+func (h *Handlers) findTaxRule(c *fiber.Ctx) (*models.TaxRule, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid tax rule ID")
+	}
+
+	var rule models.TaxRule
+	if err := h.DB.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Tax rule not found")
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get tax rule")
+	}
+
+	return &rule, nil
+}
+
+func (req *TaxRuleRequest) apply(rule *models.TaxRule) {
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Kind != nil {
+		rule.Kind = *req.Kind
+	}
+	if req.TaxType != nil {
+		rule.TaxType = *req.TaxType
+	}
+	if req.Rate != nil {
+		rule.Rate = *req.Rate
+	}
+	if req.Inclusive != nil {
+		rule.Inclusive = *req.Inclusive
+	}
+	if req.DineInOnly != nil {
+		rule.DineInOnly = *req.DineInOnly
+	}
+	if req.EffectiveFrom != nil {
+		rule.EffectiveFrom = *req.EffectiveFrom
+	}
+	if req.EffectiveTo != nil {
+		rule.EffectiveTo = req.EffectiveTo
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
+
+// validateTaxRule returns a message describing what is wrong with the rule,
+// or an empty string when it is valid.
+func validateTaxRule(rule *models.TaxRule) string {
+	switch {
+	case rule.Name == "":
+		return "Tax rule name is required"
+	case rule.Rate <= 0 || rule.Rate > 100:
+		return "Rate must be a percentage between 0 and 100"
+	case rule.EffectiveTo != nil && !rule.EffectiveTo.After(rule.EffectiveFrom):
+		return "Effective end must be after the effective start"
+	}
+
+	switch rule.Kind {
+	case models.TaxRuleKindTax:
+		if rule.TaxType != models.TaxTypePB1 && rule.TaxType != models.TaxTypePPN {
+			return "Tax type must be pb1 or ppn"
+		}
+	case models.TaxRuleKindService:
+		if rule.TaxType != "" || rule.Inclusive {
+			return "Service charge rules cannot have a tax type or be inclusive"
+		}
+	default:
+		return "Kind must be tax or service"
+	}
+	return ""
+}
@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/billing"
+	"lendral3n/ordering-system/internal/services/payment"
+	"log"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-func (h *Handlers) HandleMidtransNotification(c *fiber.Ctx) error {
+func (h *Handlers) HandlePaymentNotification(c *fiber.Ctx) error {
 	// Parse notification
 	body := c.Body()
-	notif, err := h.MidtransService.ParseNotification(body)
+	status, err := h.PaymentProvider.ParseNotification(body)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
@@ -18,79 +23,188 @@ func (h *Handlers) HandleMidtransNotification(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get payment record
-	var payment models.Payment
-	if err := h.DB.Where("midtrans_order_id = ?", notif.OrderID).First(&payment).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	// Store the event; a retried notification is acknowledged but not re-applied
+	event, duplicate, err := payment.RecordEvent(h.DB, h.PaymentProvider.Name(), status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"error":   "Payment not found",
+			"error":   "Failed to store notification",
 		})
 	}
+	if duplicate {
+		return c.SendString("OK")
+	}
 
-	// Get order
-	var order models.Order
-	if err := h.DB.Where("order_number = ?", notif.OrderID).First(&order).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	if err := h.ApplyTransactionStatus(status); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "Payment not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"error":   "Order not found",
+			"error":   "Failed to update payment",
 		})
 	}
 
-	// Update payment record
-	payment.MidtransTransactionID = &notif.TransactionID
-	payment.TransactionStatus = &notif.TransactionStatus
-	payment.PaymentType = &notif.PaymentType
-	payment.StatusMessage = &notif.StatusMessage
+	if err := payment.MarkEventProcessed(h.DB, event); err != nil {
+		log.Printf("Failed to mark payment event %d processed: %v", event.ID, err)
+	}
+
+	// Send success response
+	return c.SendString("OK")
+}
 
-	if transTime, err := notif.GetTransactionTime(); err == nil {
-		payment.TransactionTime = transTime
+// ApplyTransactionStatus applies a provider status to every payment made
+// under its reference: one payment, or one per order of a session bill.
+// A refund on a session bill only reaches the payments it was made from.
+// Webhooks, status checks and the reconciler all go through it.
+func (h *Handlers) ApplyTransactionStatus(status *payment.TransactionStatus) error {
+	payments, bill, err := billing.ReferencePayments(h.DB, status.OrderID)
+	if err != nil {
+		return err
 	}
 
-	vaNumber := notif.GetVANumber()
-	bank := notif.GetBank()
-	payment.VANumber = &vaNumber
-	payment.Bank = &bank
-	payment.FraudStatus = &notif.FraudStatus
+	if bill != nil && payment.StatusAdvances(bill.Status, status.Status) {
+		if err := h.DB.Model(bill).Update("status", status.Status).Error; err != nil {
+			return err
+		}
+	}
 
-	if err := h.DB.Save(&payment).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   "Failed to update payment",
-		})
+	refund := status.Status == models.PaymentStatusRefunded || status.Status == models.PaymentStatusPartiallyRefunded
+	for i := range payments {
+		paymentStatus := status
+		if bill != nil && refund {
+			if paymentStatus, err = payment.PaymentRefundStatus(h.DB, &payments[i], status); err != nil {
+				return err
+			}
+			if paymentStatus == nil {
+				continue
+			}
+		}
+
+		if _, err := h.applyPaymentStatus(&payments[i], paymentStatus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPaymentStatus stores a provider status on the payment and moves the
+// order's payment status along with it. Webhooks and status checks share it.
+func (h *Handlers) applyPaymentStatus(paymentRecord *models.Payment, status *payment.TransactionStatus) (*models.Order, error) {
+	var order models.Order
+	if err := h.DB.First(&order, paymentRecord.OrderID).Error; err != nil {
+		return nil, err
+	}
+
+	// Late or replayed updates never move a payment backwards. An update in
+	// the same status only refreshes its details.
+	previous := paymentRecord.Status
+	advances := payment.StatusAdvances(previous, status.Status)
+	if !advances && previous != status.Status {
+		return &order, nil
+	}
+
+	// Update payment record
+	paymentRecord.Status = status.Status
+	paymentRecord.TransactionStatus = &status.ProviderStatus
+	if status.TransactionID != "" {
+		paymentRecord.MidtransTransactionID = &status.TransactionID
+	}
+	paymentRecord.PaymentType = &status.PaymentType
+	paymentRecord.StatusMessage = &status.StatusMessage
+	if status.TransactionTime != nil {
+		paymentRecord.TransactionTime = status.TransactionTime
+	}
+	if status.VANumber != "" {
+		paymentRecord.VANumber = &status.VANumber
+		paymentRecord.Bank = &status.Bank
+	}
+	paymentRecord.FraudStatus = &status.FraudStatus
+	if status.Raw != nil {
+		paymentRecord.RawResponse = status.Raw
+	}
+
+	// Only the request that moves the payment out of its previous status
+	// applies the side effects below
+	result := h.DB.Model(paymentRecord).
+		Where("status = ?", previous).
+		Select("status", "transaction_status", "midtrans_transaction_id", "payment_type", "status_message",
+			"transaction_time", "va_number", "bank", "fraud_status", "raw_response", "updated_at").
+		Updates(paymentRecord)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || !advances {
+		return &order, nil
 	}
 
-	// Update order payment status based on transaction status
-	switch notif.TransactionStatus {
-	case "capture", "settlement":
-		// Payment successful
-		h.DB.Model(&order).Updates(map[string]interface{}{
-			"payment_status": models.PaymentStatusPaid,
-			"payment_method": notif.PaymentType,
+	// A share of a split bill only settles the order with the last share
+	if paymentRecord.SplitShareID != nil {
+		var settled bool
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			settled, err = billing.UpdateShare(tx, *paymentRecord.SplitShareID, paymentRecord.ID, status.Status)
+			return err
 		})
+		if err != nil {
+			return nil, err
+		}
+		if !settled {
+			return &order, nil
+		}
+	}
 
-		// Create notification
-		notification := models.Notification{
-			OrderID: &order.ID,
-			Type:    models.NotificationPaymentReceived,
-			Message: fmt.Sprintf("Payment received for order #%s", order.OrderNumber),
+	// A pending or failed attempt only shows on an order that is not paid,
+	// so a superseded checkout expiring cannot undo a cash payment or a
+	// later checkout
+	if status.Status == models.PaymentStatusPending || status.Status == models.PaymentStatusFailed {
+		result := h.DB.Model(&models.Order{}).
+			Where("id = ? AND payment_status IN ?", order.ID,
+				[]string{models.PaymentStatusUnpaid, models.PaymentStatusPending, models.PaymentStatusFailed}).
+			Where("NOT EXISTS (SELECT 1 FROM payments WHERE payments.order_id = orders.id AND payments.id <> ? AND payments.status IN ? AND payments.deleted_at IS NULL)",
+				paymentRecord.ID, []string{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded}).
+			Update("payment_status", status.Status)
+		if result.Error != nil {
+			return nil, result.Error
 		}
-		h.DB.Create(&notification)
+		if result.RowsAffected > 0 {
+			order.PaymentStatus = status.Status
+		}
+		return &order, nil
+	}
 
-		go h.NotificationHub.BroadcastPaymentReceived(&payment, &order)
+	// Refunds made at the gateway are mirrored onto the order
+	if status.Status != models.PaymentStatusPaid {
+		if err := h.DB.Model(&order).Update("payment_status", status.Status).Error; err != nil {
+			return nil, err
+		}
+		return &order, nil
+	}
 
-	case "pending":
-		// Payment pending
-		h.DB.Model(&order).Update("payment_status", models.PaymentStatusPending)
+	wasPaid := order.PaymentStatus == models.PaymentStatusPaid
+	err := h.DB.Model(&order).Updates(map[string]interface{}{
+		"payment_status": models.PaymentStatusPaid,
+		"payment_method": status.PaymentType,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
 
-	case "deny", "cancel", "expire":
-		// Payment failed
-		h.DB.Model(&order).Update("payment_status", models.PaymentStatusFailed)
+	// Create notification
+	notification := models.Notification{
+		OrderID: &order.ID,
+		Type:    models.NotificationPaymentReceived,
+		Message: fmt.Sprintf("Payment received for order #%s", order.OrderNumber),
+	}
+	h.DB.Create(&notification)
 
-	case "refund":
-		// Payment refunded
-		h.DB.Model(&order).Update("payment_status", models.PaymentStatusRefunded)
+	go h.NotificationHub.BroadcastPaymentReceived(paymentRecord, &order)
+	if !wasPaid {
+		go h.autoPrintReceipt(order.ID)
 	}
 
-	// Send success response
-	return c.SendString("OK")
+	return &order, nil
 }
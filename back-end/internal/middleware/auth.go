@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/repository"
+	"lendral3n/ordering-system/internal/services/auth"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Context keys for the authenticated staff member
+const (
+	LocalStaff     = "staff"
+	LocalStaffID   = "staff_id"
+	LocalStaffRole = "staff_role"
+)
+
+// StaffAuth validates the bearer access token and loads the staff member.
+// Deactivated staff are rejected even if their token has not expired yet.
+func StaffAuth(tokenService *auth.TokenService, staffRepo repository.StaffRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" || !strings.HasPrefix(header, "Bearer ") {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Authorization token required",
+			})
+		}
+
+		claims, err := tokenService.ParseAccessToken(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid or expired token",
+			})
+		}
+
+		staff, err := staffRepo.GetByID(claims.StaffID)
+		if err != nil || !staff.IsActive {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Staff account is not active",
+			})
+		}
+
+		c.Locals(LocalStaff, staff)
+		c.Locals(LocalStaffID, staff.ID)
+		c.Locals(LocalStaffRole, staff.Role)
+
+		return c.Next()
+	}
+}
+
+// RequireRoles allows the request only if the authenticated staff member has
+// one of the given roles. Admins are always allowed.
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals(LocalStaffRole).(string)
+		if role == models.StaffRoleAdmin {
+			return c.Next()
+		}
+
+		for _, r := range roles {
+			if role == r {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Insufficient permissions",
+		})
+	}
+}
+
+// CurrentStaff returns the staff member set by StaffAuth, or nil.
+func CurrentStaff(c *fiber.Ctx) *models.Staff {
+	staff, _ := c.Locals(LocalStaff).(*models.Staff)
+	return staff
+}
+
+// RequirePasswordChanged blocks staff whose password was reset by an admin
+// until they have chosen a new one.
+func RequirePasswordChanged() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if staff := CurrentStaff(c); staff != nil && staff.MustChangePassword {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error":   "Password change required",
+			})
+		}
+		return c.Next()
+	}
+}
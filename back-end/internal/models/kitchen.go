@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// Preparation station constants
+const (
+	StationKitchen = "kitchen"
+	StationGrill   = "grill"
+	StationBar     = "bar"
+	StationDessert = "dessert"
+)
+
+// Stations lists the valid preparation stations
+var Stations = []string{StationKitchen, StationGrill, StationBar, StationDessert}
+
+// KitchenTicket model - one per order and station
+type KitchenTicket struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OrderID    uint       `gorm:"not null;uniqueIndex:idx_kitchen_ticket_order_station" json:"order_id"`
+	Station    string     `gorm:"not null;uniqueIndex:idx_kitchen_ticket_order_station" json:"station"`
+	Status     string     `gorm:"default:'pending'" json:"status"` // pending, printed, failed
+	PrintCount int        `gorm:"default:0" json:"print_count"`
+	PrintedAt  *time.Time `json:"printed_at"`
+	LastError  *string    `json:"last_error"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+// Kitchen ticket status constants
+const (
+	TicketStatusPending = "pending"
+	TicketStatusPrinted = "printed"
+	TicketStatusFailed  = "failed"
+)
@@ -12,6 +12,8 @@ type MenuCategory struct {
 	Name         string         `gorm:"not null" json:"name"`
 	Description  *string        `json:"description"`
 	DisplayOrder int            `gorm:"default:0" json:"display_order"`
+	Station      string         `gorm:"default:'kitchen'" json:"station"` // preparation station for items in this category
+	TaxExempt    bool           `gorm:"default:false" json:"tax_exempt"`
 	IsActive     bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -27,13 +29,15 @@ type MenuItem struct {
 	CategoryID      uint           `gorm:"not null" json:"category_id"`
 	Name            string         `gorm:"not null" json:"name"`
 	Description     *string        `json:"description"`
-	Price           float64        `gorm:"not null" json:"price"`
+	Price           Money          `gorm:"not null" json:"price"`
 	ImageURL        *string        `json:"image_url"`
 	Image360URL     *string        `json:"image_360_url"`
 	VideoURL        *string        `json:"video_url"`
 	IsAvailable     bool           `gorm:"default:true" json:"is_available"`
 	PreparationTime *int           `json:"preparation_time"` // in minutes
 	StockQuantity   *int           `json:"stock_quantity"`   // NULL = unlimited
+	Station         *string        `json:"station"`          // NULL = category station
+	TaxExempt       *bool          `json:"tax_exempt"`       // NULL = category setting
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -42,3 +46,24 @@ type MenuItem struct {
 	Category   MenuCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	MediaFiles []MediaFile  `json:"media_files,omitempty"`
 }
+
+// StationName returns the station that prepares the item, falling back to
+// its category's station. Category must be loaded.
+func (m *MenuItem) StationName() string {
+	if m.Station != nil && *m.Station != "" {
+		return *m.Station
+	}
+	if m.Category.Station != "" {
+		return m.Category.Station
+	}
+	return StationKitchen
+}
+
+// IsTaxExempt reports whether tax rules skip the item, falling back to its
+// category's setting. Category must be loaded.
+func (m *MenuItem) IsTaxExempt() bool {
+	if m.TaxExempt != nil {
+		return *m.TaxExempt
+	}
+	return m.Category.TaxExempt
+}
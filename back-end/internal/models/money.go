@@ -0,0 +1,117 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Money is an amount in whole rupiah. The rupiah has no subunit in use and
+// Midtrans only accepts whole amounts, so storing integers keeps totals,
+// shares and gateway line items exact. Fractions only appear when a rate is
+// applied and are rounded once, to the nearest rupiah, at that point.
+type Money int64
+
+// NewMoney rounds a decimal amount to the nearest rupiah, halves away from zero.
+func NewMoney(amount float64) Money {
+	return Money(math.Round(amount))
+}
+
+// Percent returns rate percent of m rounded to the nearest rupiah. Tax and
+// service charge are rounded this way once per order, never per item.
+func (m Money) Percent(rate float64) Money {
+	return NewMoney(float64(m) * rate / 100)
+}
+
+// Times returns m multiplied by a quantity.
+func (m Money) Times(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// Allocate divides m in proportion to weights, rounding each part to the
+// nearest rupiah. The last part absorbs the rounding difference so the parts
+// always add up to m. Weights that are all zero split m evenly.
+func (m Money) Allocate(weights []Money) []Money {
+	var sum Money
+	for _, w := range weights {
+		sum += w
+	}
+
+	parts := make([]Money, len(weights))
+	remaining := m
+	for i, w := range weights {
+		if i == len(weights)-1 {
+			parts[i] = remaining
+			break
+		}
+		if sum == 0 {
+			parts[i] = NewMoney(float64(m) / float64(len(weights)))
+		} else {
+			parts[i] = NewMoney(float64(m) * float64(w) / float64(sum))
+		}
+		remaining -= parts[i]
+	}
+	return parts
+}
+
+// Float64 returns m for display and ratio calculations.
+func (m Money) Float64() float64 {
+	return float64(m)
+}
+
+// UnmarshalJSON accepts a number or a numeric string and rounds it to the
+// nearest rupiah, so clients sending decimals keep working.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" || len(data) == 0 {
+		*m = 0
+		return nil
+	}
+
+	amount, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	*m = NewMoney(amount)
+	return nil
+}
+
+// Scan reads whole rupiah columns as well as numeric results such as SUM().
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = NewMoney(v)
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", value)
+	}
+	return nil
+}
+
+func (m *Money) scanString(value string) error {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into Money: %w", value, err)
+	}
+	*m = NewMoney(amount)
+	return nil
+}
+
+// Value stores m as a bigint.
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// GormDataType keeps money columns as bigint.
+func (Money) GormDataType() string {
+	return "bigint"
+}
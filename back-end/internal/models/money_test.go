@@ -0,0 +1,43 @@
+package models
+
+import "testing"
+
+func TestMoneyAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Money
+		weights []Money
+		want    []Money
+	}{
+		{"exact proportions", 10000, []Money{3000, 7000}, []Money{3000, 7000}},
+		{"last part absorbs the remainder", 100, []Money{1, 1, 1}, []Money{33, 33, 34}},
+		{"parts round to nearest", 100, []Money{1, 2}, []Money{33, 67}},
+		{"halves round away from zero", 101, []Money{1, 1}, []Money{51, 50}},
+		{"uneven weights", 78500, []Money{15000, 20000, 35000}, []Money{16821, 22429, 39250}},
+		{"all zero weights split evenly", 100, []Money{0, 0, 0}, []Money{33, 33, 34}},
+		{"zero amount", 0, []Money{5, 5}, []Money{0, 0}},
+		{"single part", 500, []Money{0}, []Money{500}},
+		{"no parts", 500, nil, []Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.amount.Allocate(tt.weights)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Allocate(%v) = %v, want %v", tt.weights, got, tt.want)
+			}
+
+			var sum Money
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Allocate(%v) = %v, want %v", tt.weights, got, tt.want)
+					break
+				}
+				sum += got[i]
+			}
+			if len(got) > 0 && sum != tt.amount {
+				t.Errorf("parts add up to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,12 +13,16 @@ type Order struct {
 	OrderNumber   string         `gorm:"uniqueIndex;not null" json:"order_number"`
 	SessionID     uint           `gorm:"not null" json:"session_id"`
 	TableID       uint           `gorm:"not null" json:"table_id"`
-	Status        string         `gorm:"default:'pending'" json:"status"` // pending, confirmed, preparing, ready, served, completed, cancelled
-	TotalAmount   float64        `gorm:"not null" json:"total_amount"`
-	TaxAmount     float64        `gorm:"default:0" json:"tax_amount"`
-	ServiceCharge float64        `gorm:"default:0" json:"service_charge"`
-	GrandTotal    float64        `gorm:"not null" json:"grand_total"`
-	PaymentStatus string         `gorm:"default:'unpaid'" json:"payment_status"` // unpaid, pending, paid, failed, refunded
+	Status        string         `gorm:"default:'pending'" json:"status"`     // pending, confirmed, preparing, ready, served, completed, cancelled
+	OrderType     string         `gorm:"default:'dine_in'" json:"order_type"` // dine_in, takeaway
+	TotalAmount   Money          `gorm:"not null" json:"total_amount"`
+	TaxRate       float64        `gorm:"default:0" json:"tax_rate"` // percentage applied when the order was placed
+	TaxAmount     Money          `gorm:"default:0" json:"tax_amount"`
+	ServiceRate   float64        `gorm:"default:0" json:"service_rate"` // percentage applied when the order was placed
+	ServiceCharge Money          `gorm:"default:0" json:"service_charge"`
+	IncludedTax   Money          `gorm:"default:0" json:"included_tax"` // part of TotalAmount under tax-inclusive pricing
+	GrandTotal    Money          `gorm:"not null" json:"grand_total"`
+	PaymentStatus string         `gorm:"default:'unpaid'" json:"payment_status"` // unpaid, pending, paid, failed, refunded, partially_refunded
 	PaymentMethod *string        `json:"payment_method"`
 	Notes         *string        `json:"notes"`
 	CreatedAt     time.Time      `json:"created_at"`
@@ -29,6 +34,68 @@ type Order struct {
 	Table           Table           `gorm:"foreignKey:TableID" json:"table,omitempty"`
 	OrderItems      []OrderItem     `json:"order_items,omitempty"`
 	Payment         *Payment        `json:"payment,omitempty"`
+	Timeline        []OrderEvent    `gorm:"foreignKey:OrderID" json:"timeline,omitempty"`
+	Charges         []OrderCharge   `gorm:"foreignKey:OrderID" json:"charges,omitempty"`
+}
+
+// EffectiveTaxRate returns the tax percentage the order was priced with.
+// Orders placed before rates were stored fall back to deriving it from the amounts.
+func (o *Order) EffectiveTaxRate() float64 {
+	if o.TaxRate > 0 || o.TotalAmount == 0 {
+		return o.TaxRate
+	}
+	return math.Round(o.TaxAmount.Float64()/o.TotalAmount.Float64()*10000) / 100
+}
+
+// EffectiveServiceRate is the service charge counterpart of EffectiveTaxRate.
+func (o *Order) EffectiveServiceRate() float64 {
+	if o.ServiceRate > 0 || o.TotalAmount == 0 {
+		return o.ServiceRate
+	}
+	return math.Round(o.ServiceCharge.Float64()/o.TotalAmount.Float64()*10000) / 100
+}
+
+// DeriveOrderStatus rolls item statuses up into an order status. Cancelled
+// items are ignored unless every item is cancelled. Completed and cancelled
+// orders are final and keep their status.
+func DeriveOrderStatus(current string, items []OrderItem) string {
+	if current == OrderStatusCompleted || current == OrderStatusCancelled || len(items) == 0 {
+		return current
+	}
+
+	var active, started, ready, served int
+	for _, item := range items {
+		switch item.Status {
+		case OrderItemStatusCancelled:
+			continue
+		case OrderItemStatusPreparing:
+			started++
+		case OrderItemStatusReady:
+			started++
+			ready++
+		case OrderItemStatusServed:
+			started++
+			served++
+		}
+		active++
+	}
+
+	switch {
+	case active == 0:
+		return OrderStatusCancelled
+	case served == active:
+		return OrderStatusServed
+	case ready+served == active:
+		return OrderStatusReady
+	case started > 0:
+		return OrderStatusPreparing
+	}
+
+	// Nothing started yet; a recalled order steps back to confirmed
+	if current == OrderStatusPending || current == OrderStatusConfirmed {
+		return current
+	}
+	return OrderStatusConfirmed
 }
 
 // OrderItem model
@@ -37,8 +104,9 @@ type OrderItem struct {
 	OrderID    uint           `gorm:"not null" json:"order_id"`
 	MenuItemID uint           `gorm:"not null" json:"menu_item_id"`
 	Quantity   int            `gorm:"not null" json:"quantity"`
-	UnitPrice  float64        `gorm:"not null" json:"unit_price"`
-	Subtotal   float64        `gorm:"not null" json:"subtotal"`
+	UnitPrice  Money          `gorm:"not null" json:"unit_price"`
+	Subtotal   Money          `gorm:"not null" json:"subtotal"`
+	TaxExempt  bool           `gorm:"default:false" json:"tax_exempt"` // as the menu item was when ordered
 	Notes      *string        `json:"notes"`
 	Status     string         `gorm:"default:'pending'" json:"status"` // pending, preparing, ready, served, cancelled
 	CreatedAt  time.Time      `json:"created_at"`
@@ -61,13 +129,20 @@ const (
 	OrderStatusCancelled = "cancelled"
 )
 
+// Order type constants
+const (
+	OrderTypeDineIn   = "dine_in"
+	OrderTypeTakeaway = "takeaway"
+)
+
 // Payment status constants
 const (
-	PaymentStatusUnpaid   = "unpaid"
-	PaymentStatusPending  = "pending"
-	PaymentStatusPaid     = "paid"
-	PaymentStatusFailed   = "failed"
-	PaymentStatusRefunded = "refunded"
+	PaymentStatusUnpaid            = "unpaid"
+	PaymentStatusPending           = "pending"
+	PaymentStatusPaid              = "paid"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
 )
 
 // Order item status constants
@@ -78,3 +153,40 @@ const (
 	OrderItemStatusServed    = "served"
 	OrderItemStatusCancelled = "cancelled"
 )
+
+// Allowed status transitions. Kitchen recalls may step back one stage;
+// completed orders can only be cancelled (for refunds) and cancelled is final.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusPreparing, OrderStatusReady, OrderStatusServed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPreparing, OrderStatusReady, OrderStatusServed, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusConfirmed, OrderStatusReady, OrderStatusServed, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusPreparing, OrderStatusServed, OrderStatusCancelled},
+	OrderStatusServed:    {OrderStatusReady, OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusCompleted: {OrderStatusCancelled},
+}
+
+var orderItemTransitions = map[string][]string{
+	OrderItemStatusPending:   {OrderItemStatusPreparing, OrderItemStatusReady, OrderItemStatusServed, OrderItemStatusCancelled},
+	OrderItemStatusPreparing: {OrderItemStatusPending, OrderItemStatusReady, OrderItemStatusServed, OrderItemStatusCancelled},
+	OrderItemStatusReady:     {OrderItemStatusPreparing, OrderItemStatusServed, OrderItemStatusCancelled},
+	OrderItemStatusServed:    {OrderItemStatusReady, OrderItemStatusCancelled},
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another.
+func CanTransitionOrder(from, to string) bool {
+	return containsStatus(orderTransitions[from], to)
+}
+
+// CanTransitionOrderItem reports whether an order item may move from one status to another.
+func CanTransitionOrderItem(from, to string) bool {
+	return containsStatus(orderItemTransitions[from], to)
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// OrderEvent model - one row per status transition of an order or one of its items
+type OrderEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OrderID     uint      `gorm:"not null;index" json:"order_id"`
+	OrderItemID *uint     `gorm:"index" json:"order_item_id"` // NULL = the order itself
+	FromStatus  string    `json:"from_status"`                // empty when the order was created
+	ToStatus    string    `gorm:"not null" json:"to_status"`
+	StaffID     *uint     `json:"staff_id"` // NULL = customer or system
+	Reason      *string   `json:"reason"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+
+	// Relations
+	Staff *Staff `gorm:"foreignKey:StaffID" json:"staff,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,23 +9,45 @@ import (
 
 // Payment model
 type Payment struct {
-	ID                    uint           `gorm:"primaryKey" json:"id"`
-	OrderID               uint           `gorm:"not null" json:"order_id"`
-	MidtransOrderID       string         `gorm:"uniqueIndex" json:"midtrans_order_id"`
-	MidtransTransactionID *string        `json:"midtrans_transaction_id"`
-	PaymentType           *string        `json:"payment_type"`
-	TransactionStatus     *string        `json:"transaction_status"`
-	TransactionTime       *time.Time     `json:"transaction_time"`
-	GrossAmount           float64        `gorm:"not null" json:"gross_amount"`
-	Currency              string         `gorm:"default:'IDR'" json:"currency"`
-	VANumber              *string        `json:"va_number"`
-	Bank                  *string        `json:"bank"`
-	FraudStatus           *string        `json:"fraud_status"`
-	StatusMessage         *string        `json:"status_message"`
-	CreatedAt             time.Time      `json:"created_at"`
-	UpdatedAt             time.Time      `json:"updated_at"`
-	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                    uint            `gorm:"primaryKey" json:"id"`
+	OrderID               uint            `gorm:"not null" json:"order_id"`
+	SplitShareID          *uint           `gorm:"index" json:"split_share_id,omitempty"`  // set when paying one share of a split bill
+	SessionBillID         *uint           `gorm:"index" json:"session_bill_id,omitempty"` // set when paid with the rest of the session
+	Provider              string          `gorm:"default:'midtrans'" json:"provider"`
+	Method                string          `gorm:"default:'gateway'" json:"method"`       // gateway, cash, edc
+	Status                string          `gorm:"default:'pending';index" json:"status"` // normalized, see PaymentStatus constants
+	MidtransOrderID       string          `gorm:"uniqueIndex" json:"midtrans_order_id"`  // order reference sent to the provider
+	MidtransTransactionID *string         `json:"midtrans_transaction_id"`
+	PaymentType           *string         `json:"payment_type"`
+	TransactionStatus     *string         `json:"transaction_status"` // raw provider status
+	TransactionTime       *time.Time      `json:"transaction_time"`
+	GrossAmount           Money           `gorm:"not null" json:"gross_amount"`
+	TipAmount             Money           `gorm:"default:0" json:"tip_amount"` // included in GrossAmount, kept apart from the order's service charge
+	Currency              string          `gorm:"default:'IDR'" json:"currency"`
+	VANumber              *string         `json:"va_number"`
+	Bank                  *string         `json:"bank"`
+	FraudStatus           *string         `json:"fraud_status"`
+	StatusMessage         *string         `json:"status_message"`
+	QRString              *string         `json:"qr_string,omitempty"`       // QRIS payload to scan, QRIS only
+	ExpiresAt             *time.Time      `json:"expires_at,omitempty"`      // when an unpaid QRIS code stops working
+	RawResponse           json.RawMessage `gorm:"type:jsonb" json:"-"`       // latest provider payload
+	TenderedAmount        *Money          `json:"tendered_amount,omitempty"` // cash only
+	ChangeAmount          *Money          `json:"change_amount,omitempty"`   // cash only
+	ApprovalCode          *string         `json:"approval_code,omitempty"`   // EDC only
+	StaffID               *uint           `json:"staff_id,omitempty"`        // cashier who recorded a manual payment
+	LastCheckedAt         *time.Time      `json:"last_checked_at,omitempty"` // when the reconciler last asked the provider
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt  `gorm:"index" json:"-"`
 	
 	// Relations
-	Order Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
-}
\ No newline at end of file
+	Order   Order    `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	Refunds []Refund `gorm:"foreignKey:PaymentID" json:"refunds,omitempty"`
+}
+
+// Payment method constants
+const (
+	PaymentMethodGateway = "gateway"
+	PaymentMethodCash    = "cash"
+	PaymentMethodEDC     = "edc"
+)
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PaymentEvent model - a webhook notification as received from the payment
+// provider. The same transaction status is only processed once.
+type PaymentEvent struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	Provider       string          `gorm:"not null" json:"provider"`
+	Reference      string          `gorm:"not null;index" json:"reference"` // provider order reference
+	TransactionID  string          `gorm:"not null;uniqueIndex:idx_payment_event" json:"transaction_id"`
+	ProviderStatus string          `gorm:"not null;uniqueIndex:idx_payment_event" json:"provider_status"`
+	FraudStatus    string          `gorm:"uniqueIndex:idx_payment_event" json:"fraud_status"` // a challenged capture is resent once accepted
+	Status         string          `gorm:"not null" json:"status"`                            // normalized payment status
+	RawPayload     json.RawMessage `gorm:"type:jsonb" json:"raw_payload"`
+	ProcessedAt    *time.Time      `json:"processed_at"` // NULL until applied, so a failed delivery is retried
+	CreatedAt      time.Time       `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// ReconciliationReport model - one day of gateway payments compared with the
+// payment provider
+type ReconciliationReport struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Date          time.Time `gorm:"type:date;uniqueIndex;not null" json:"date"`
+	Provider      string    `gorm:"not null" json:"provider"`
+	Checked       int       `json:"checked"` // payments compared
+	MismatchCount int       `json:"mismatch_count"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Relations
+	Mismatches []ReconciliationMismatch `gorm:"foreignKey:ReportID" json:"mismatches,omitempty"`
+}
+
+// ReconciliationMismatch model - a payment that disagrees with the provider
+type ReconciliationMismatch struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	ReportID       uint   `gorm:"not null;index" json:"report_id"`
+	PaymentID      uint   `gorm:"not null;index" json:"payment_id"`
+	Reference      string `gorm:"not null" json:"reference"` // provider order reference
+	Issue          string `gorm:"not null" json:"issue"`     // status, amount, missing, error
+	LocalStatus    string `json:"local_status"`
+	ProviderStatus string `json:"provider_status"` // normalized, empty when missing
+	LocalAmount    Money  `json:"local_amount"`
+	ProviderAmount *Money `json:"provider_amount"`
+	Detail         string `json:"detail"`
+}
+
+// Reconciliation issue constants
+const (
+	ReconciliationIssueStatus  = "status"
+	ReconciliationIssueAmount  = "amount"
+	ReconciliationIssueMissing = "missing"
+	ReconciliationIssueError   = "error"
+)
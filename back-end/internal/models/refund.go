@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Refund model - a full or partial refund issued against a payment
+type Refund struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	PaymentID        uint      `gorm:"not null;index" json:"payment_id"`
+	OrderID          uint      `gorm:"not null;index" json:"order_id"`
+	RefundKey        string    `gorm:"uniqueIndex;not null" json:"refund_key"` // idempotency key sent to the gateway
+	Amount           Money     `gorm:"not null" json:"amount"`
+	Reason           string    `json:"reason"`
+	Status           string    `gorm:"default:'pending'" json:"status"` // pending, succeeded, failed
+	ProviderRefundID *string   `json:"provider_refund_id"`
+	FailureMessage   *string   `json:"failure_message"`
+	StaffID          *uint     `json:"staff_id"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Relations
+	Payment *Payment `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+}
+
+// Refund status constants
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
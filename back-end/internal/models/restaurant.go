@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// RestaurantProfile holds the branding printed on invoices and receipts.
+// There is a single row with ID RestaurantProfileID.
+type RestaurantProfile struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	Name               string    `gorm:"not null" json:"name"`
+	Address            string    `json:"address"`
+	Phone              string    `json:"phone"`
+	Email              string    `json:"email"`
+	TaxID              string    `json:"tax_id"` // NPWP
+	LogoURL            *string   `json:"logo_url"`
+	FooterText         string    `json:"footer_text"`
+	TaxLabel           string    `gorm:"default:'Tax'" json:"tax_label"` // e.g. PB1, PPN
+	ServiceChargeLabel string    `gorm:"default:'Service Charge'" json:"service_charge_label"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+const RestaurantProfileID = 1
+
+// DefaultRestaurantProfile is used until an admin saves the real profile.
+func DefaultRestaurantProfile() RestaurantProfile {
+	return RestaurantProfile{
+		ID:                 RestaurantProfileID,
+		Name:               "Restaurant Name",
+		Address:            "Jl. Example Street No. 123, Jakarta, Indonesia",
+		FooterText:         "Thank you for dining with us! Please visit us again",
+		TaxLabel:           "Tax",
+		ServiceChargeLabel: "Service Charge",
+	}
+}
\ No newline at end of file
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// SessionBill model - the unpaid orders of a customer session paid together
+// in one gateway transaction. Every order keeps its own Payment row pointing
+// at the bill, so refunds and receipts stay per order.
+type SessionBill struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	SessionID   uint      `gorm:"not null;index" json:"session_id"`
+	Reference   string    `gorm:"uniqueIndex;not null" json:"reference"` // order reference sent to the provider
+	Status      string    `gorm:"default:'pending'" json:"status"`       // pending, paid, failed
+	TotalAmount Money     `gorm:"not null" json:"total_amount"`          // tip included
+	TipAmount   Money     `gorm:"default:0" json:"tip_amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relations
+	Payments []Payment `gorm:"foreignKey:SessionBillID" json:"payments,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// SplitBill model - an order's grand total divided into shares that are
+// paid separately. The order is paid once every share is.
+type SplitBill struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OrderID     uint      `gorm:"not null;index" json:"order_id"`
+	Mode        string    `gorm:"not null" json:"mode"`         // item, equal, custom
+	Status      string    `gorm:"default:'open'" json:"status"` // open, settled, cancelled
+	TotalAmount Money     `gorm:"not null" json:"total_amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relations
+	Shares []SplitShare `gorm:"foreignKey:SplitBillID" json:"shares,omitempty"`
+}
+
+// SplitShare model - one guest's part of a split bill
+type SplitShare struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	SplitBillID uint      `gorm:"not null;index" json:"split_bill_id"`
+	Label       string    `json:"label"`
+	Amount      Money     `gorm:"not null" json:"amount"`
+	Status      string    `gorm:"default:'unpaid'" json:"status"` // unpaid, pending, paid
+	PaymentID   *uint     `json:"payment_id"`                     // latest payment attempt
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relations
+	Items   []SplitShareItem `gorm:"foreignKey:SplitShareID" json:"items,omitempty"`
+	Payment *Payment         `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+}
+
+// SplitShareItem model - an order item assigned to a share when splitting by item
+type SplitShareItem struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	SplitShareID uint `gorm:"not null;index" json:"split_share_id"`
+	OrderItemID  uint `gorm:"not null;index" json:"order_item_id"`
+
+	// Relations
+	OrderItem *OrderItem `gorm:"foreignKey:OrderItemID" json:"order_item,omitempty"`
+}
+
+// Split bill mode constants
+const (
+	SplitModeItem   = "item"
+	SplitModeEqual  = "equal"
+	SplitModeCustom = "custom"
+)
+
+// Split bill status constants
+const (
+	SplitBillStatusOpen      = "open"
+	SplitBillStatusSettled   = "settled"
+	SplitBillStatusCancelled = "cancelled"
+)
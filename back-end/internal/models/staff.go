@@ -9,17 +9,18 @@ import (
 
 // Staff model
 type Staff struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Username     string         `gorm:"uniqueIndex;not null" json:"username"`
-	Email        string         `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash string         `gorm:"not null" json:"-"`
-	FullName     string         `gorm:"not null" json:"full_name"`
-	Role         string         `gorm:"not null" json:"role"` // admin, cashier, waiter, kitchen
-	IsActive     bool           `gorm:"default:true" json:"is_active"`
-	LastLogin    *time.Time     `json:"last_login"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Username           string         `gorm:"uniqueIndex;not null" json:"username"`
+	Email              string         `gorm:"uniqueIndex;not null" json:"email"`
+	PasswordHash       string         `gorm:"not null" json:"-"`
+	FullName           string         `gorm:"not null" json:"full_name"`
+	Role               string         `gorm:"not null" json:"role"` // admin, cashier, waiter, kitchen
+	IsActive           bool           `gorm:"default:true" json:"is_active"`
+	MustChangePassword bool           `gorm:"default:false" json:"must_change_password"` // set by admin password reset
+	LastLogin          *time.Time     `json:"last_login"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // Staff role constants
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// StaffShift model - a staff member clocked in from StartedAt until EndedAt
+type StaffShift struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	StaffID   uint       `gorm:"not null;index" json:"staff_id"`
+	StartedAt time.Time  `gorm:"not null;index" json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"` // NULL while the shift is open
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Relations
+	Staff *Staff `gorm:"foreignKey:StaffID" json:"staff,omitempty"`
+}
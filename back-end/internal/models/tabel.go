@@ -13,6 +13,7 @@ type Table struct {
 	QRCode      string         `gorm:"not null" json:"qr_code"`
 	Status      string         `gorm:"default:'available'" json:"status"` // available, occupied, reserved
 	Capacity    int            `gorm:"not null" json:"capacity"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"` // false = retired, no new sessions
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaxRule model - a tax or service charge applied to new orders while it is
+// in effect. Orders keep a copy of the rules they were priced under, so
+// editing a rule never changes an existing bill.
+type TaxRule struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Name          string         `gorm:"not null" json:"name"`              // shown on bills, e.g. "PB1"
+	Kind          string         `gorm:"not null" json:"kind"`              // tax, service
+	TaxType       string         `json:"tax_type"`                          // pb1, ppn; tax rules only
+	Rate          float64        `gorm:"not null" json:"rate"`              // percentage
+	Inclusive     bool           `gorm:"default:false" json:"inclusive"`    // menu prices already include it; tax rules only
+	DineInOnly    bool           `gorm:"default:false" json:"dine_in_only"` // not charged on takeaway orders
+	EffectiveFrom time.Time      `gorm:"not null" json:"effective_from"`
+	EffectiveTo   *time.Time     `json:"effective_to"` // NULL = until further notice
+	IsActive      bool           `gorm:"not null" json:"is_active"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// AppliesTo reports whether the rule charges orders of the given type.
+func (r *TaxRule) AppliesTo(orderType string) bool {
+	return !r.DineInOnly || orderType == OrderTypeDineIn
+}
+
+// OrderCharge model - a tax or service charge rule as it was applied to one
+// order
+type OrderCharge struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrderID   uint      `gorm:"not null;index" json:"order_id"`
+	TaxRuleID *uint     `json:"tax_rule_id"` // NULL when priced with the environment defaults
+	Name      string    `gorm:"not null" json:"name"`
+	Kind      string    `gorm:"not null" json:"kind"`
+	TaxType   string    `json:"tax_type"`
+	Rate      float64   `gorm:"not null" json:"rate"`
+	Inclusive bool      `gorm:"default:false" json:"inclusive"`
+	Base      Money     `gorm:"not null" json:"base"`   // amount the rate was applied to
+	Amount    Money     `gorm:"not null" json:"amount"` // added to the bill, or contained in Base when inclusive
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tax rule kind constants
+const (
+	TaxRuleKindTax     = "tax"
+	TaxRuleKindService = "service"
+)
+
+// Tax type constants
+const (
+	TaxTypePB1 = "pb1" // regional restaurant tax
+	TaxTypePPN = "ppn" // value added tax
+)
@@ -30,7 +30,7 @@ type StaffRepository interface {
 type TableRepository interface {
 	GetByID(id uint) (*models.Table, error)
 	GetByTableNumber(tableNumber string) (*models.Table, error)
-	GetAll() ([]models.Table, error)
+	GetAll(activeOnly bool) ([]models.Table, error)
 	Create(table *models.Table) error
 	Update(table *models.Table) error
 	UpdateStatus(id uint, status string) error
@@ -29,9 +29,15 @@ func (r *tableRepository) GetByTableNumber(tableNumber string) (*models.Table, e
 	return &table, nil
 }
 
-func (r *tableRepository) GetAll() ([]models.Table, error) {
+func (r *tableRepository) GetAll(activeOnly bool) ([]models.Table, error) {
 	var tables []models.Table
-	if err := r.db.Order("table_number").Find(&tables).Error; err != nil {
+	query := r.db.Order("table_number")
+	
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	
+	if err := query.Find(&tables).Error; err != nil {
 		return nil, err
 	}
 	return tables, nil
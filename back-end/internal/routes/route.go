@@ -2,6 +2,8 @@ package routes
 
 import (
 	"lendral3n/ordering-system/internal/handler"
+	"lendral3n/ordering-system/internal/middleware"
+	"lendral3n/ordering-system/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -28,38 +30,117 @@ func SetupRoutes(app *fiber.App, h *handlers.Handlers) {
 	customer.Post("/orders", h.CreateOrder)
 	customer.Get("/orders/:id", h.GetOrder)
 	customer.Get("/orders/session", h.GetOrdersBySession)
+	customer.Get("/orders/:id/invoice", h.GetCustomerInvoice)
+	customer.Post("/orders/:id/cancel", h.CancelCustomerOrder)
 	customer.Post("/assistance", h.RequestAssistance)
 	
 	// Payment routes
+	customer.Get("/payment/tips", h.GetTipPresets)
 	customer.Post("/payment/create", h.CreatePayment)
+	customer.Get("/payment/session", h.GetSessionBill)
+	customer.Post("/payment/session", h.CreateSessionPayment)
 	customer.Get("/payment/:order_id/status", h.GetPaymentStatus)
 	customer.Get("/payment/finish", h.HandlePaymentFinish)
 	customer.Post("/payment/finish", h.HandlePaymentFinish)
+	customer.Post("/orders/:id/split", h.CreateCustomerSplitBill)
+	customer.Get("/orders/:id/split", h.GetCustomerSplitBill)
+	customer.Delete("/orders/:id/split", h.CancelCustomerSplitBill)
+	customer.Post("/orders/:id/split/shares/:share_id/pay", h.PaySplitShare)
 
-	// Staff routes
-	staff := api.Group("/staff")
+	// Staff authentication (public). Registered before the staff group so
+	// the auth middleware below never runs for these paths.
+	api.Post("/staff/auth/login", h.StaffLogin)
+	api.Post("/staff/auth/refresh", h.RefreshStaffToken)
+
+	// Staff routes (require authentication)
+	staff := api.Group("/staff", middleware.StaffAuth(h.TokenService, h.StaffRepository))
+	staff.Get("/auth/me", h.GetStaffProfile)
+	staff.Post("/auth/change-password", h.ChangePassword)
+
+	// Everything below requires a password change after an admin reset
+	staff.Use(middleware.RequirePasswordChanged())
+
+	// Role guards - admin is always allowed
+	adminOnly := middleware.RequireRoles(models.StaffRoleAdmin)
+	cashier := middleware.RequireRoles(models.StaffRoleCashier)
+	kitchen := middleware.RequireRoles(models.StaffRoleKitchen)
+	floor := middleware.RequireRoles(models.StaffRoleWaiter, models.StaffRoleKitchen, models.StaffRoleCashier)
+	
+	// Staff account management
+	staff.Get("/accounts", adminOnly, h.GetStaffList)
+	staff.Post("/accounts", adminOnly, h.CreateStaff)
+	staff.Get("/accounts/:id", adminOnly, h.GetStaffMember)
+	staff.Put("/accounts/:id", adminOnly, h.UpdateStaff)
+	staff.Post("/accounts/:id/reset-password", adminOnly, h.ResetStaffPassword)
+	staff.Put("/accounts/:id/deactivate", adminOnly, h.DeactivateStaff)
+	staff.Put("/accounts/:id/activate", adminOnly, h.ActivateStaff)
+	
+	// Restaurant settings
+	staff.Get("/settings/restaurant", h.GetRestaurantProfile)
+	staff.Put("/settings/restaurant", adminOnly, h.UpdateRestaurantProfile)
+	staff.Post("/settings/restaurant/logo", adminOnly, h.UploadRestaurantLogo)
+	staff.Get("/settings/tax-rules", adminOnly, h.GetTaxRules)
+	staff.Post("/settings/tax-rules", adminOnly, h.CreateTaxRule)
+	staff.Put("/settings/tax-rules/:id", adminOnly, h.UpdateTaxRule)
+	staff.Delete("/settings/tax-rules/:id", adminOnly, h.DeleteTaxRule)
+	
+	// Table management
+	staff.Get("/tables", h.GetTables)
+	staff.Post("/tables", adminOnly, h.CreateTable)
+	staff.Post("/tables/qr/regenerate", adminOnly, h.RegenerateTableQRCodes)
+	staff.Get("/tables/qr/sheet", h.GetTableQRSheet)
+	staff.Get("/tables/:id", h.GetTable)
+	staff.Put("/tables/:id", adminOnly, h.UpdateTable)
+	staff.Put("/tables/:id/retire", adminOnly, h.RetireTable)
+	staff.Put("/tables/:id/reactivate", adminOnly, h.ReactivateTable)
+	staff.Get("/tables/:id/qr", h.GetTableQRCode)
 	
 	// Order management
 	staff.Get("/orders", h.GetOrders)
-	staff.Get("/orders/:id", h.GetOrder)
-	staff.Put("/orders/:id/status", h.UpdateOrderStatus)
-	staff.Put("/orders/items/:item_id/status", h.UpdateOrderItemStatus)
+	staff.Get("/orders/:id", h.GetStaffOrder)
+	staff.Get("/orders/:id/invoice", cashier, h.GetOrderInvoice)
+	staff.Get("/orders/:id/receipt", cashier, h.GetOrderReceipt)
+	staff.Post("/orders/:id/receipt/print", cashier, h.PrintOrderReceipt)
+	staff.Put("/orders/:id/status", floor, h.UpdateOrderStatus)
+	staff.Post("/orders/:id/cancel", floor, h.CancelOrder)
+	staff.Put("/orders/items/:item_id/status", kitchen, h.UpdateOrderItemStatus)
+	staff.Get("/orders/:id/tickets", floor, h.GetOrderTickets)
+	staff.Post("/kitchen/tickets/:id/reprint", floor, h.ReprintKitchenTicket)
+	
+	// Kitchen display
+	staff.Get("/kds/stations", kitchen, h.GetKitchenStations)
+	staff.Get("/kds/stations/:station", kitchen, h.GetStationQueue)
+	staff.Put("/kds/items/:id/bump", kitchen, h.BumpOrderItem)
+	staff.Put("/kds/items/:id/recall", kitchen, h.RecallOrderItem)
 	
 	// Menu management
 	staff.Get("/menu/categories", h.GetCategories)
-	staff.Post("/menu/categories", h.CreateCategory)
-	staff.Put("/menu/categories/:id", h.UpdateCategory)
+	staff.Post("/menu/categories", adminOnly, h.CreateCategory)
+	staff.Put("/menu/categories/:id", adminOnly, h.UpdateCategory)
 	
 	staff.Get("/menu/items", h.GetMenuItems)
-	staff.Post("/menu/items", h.CreateMenuItem)
-	staff.Put("/menu/items/:id", h.UpdateMenuItem)
-	staff.Delete("/menu/items/:id", h.DeleteMenuItem)
-	staff.Post("/menu/items/:id/media", h.UploadMedia)
-	staff.Put("/menu/items/:id/stock", h.UpdateStock)
+	staff.Post("/menu/items", adminOnly, h.CreateMenuItem)
+	staff.Put("/menu/items/:id", adminOnly, h.UpdateMenuItem)
+	staff.Delete("/menu/items/:id", adminOnly, h.DeleteMenuItem)
+	staff.Post("/menu/items/:id/media", adminOnly, h.UploadMedia)
+	staff.Put("/menu/items/:id/stock", kitchen, h.UpdateStock)
 	
 	// Payment routes
-	staff.Get("/payments", h.GetPayments)
-	staff.Put("/payments/:id/verify", h.VerifyPayment)
+	staff.Get("/payments", cashier, h.GetPayments)
+	staff.Post("/payments/manual", cashier, h.RecordManualPayment)
+	staff.Get("/payments/reconciliation", adminOnly, h.GetReconciliationReports)
+	staff.Get("/payments/reconciliation/:id", adminOnly, h.GetReconciliationReport)
+	staff.Post("/orders/:id/split", cashier, h.CreateSplitBill)
+	staff.Get("/orders/:id/split", cashier, h.GetSplitBill)
+	staff.Delete("/orders/:id/split", cashier, h.CancelSplitBill)
+	staff.Put("/payments/:id/verify", cashier, h.VerifyPayment)
+	staff.Post("/payments/:id/refund", cashier, h.RefundPayment)
+	staff.Get("/payments/:id/refunds", cashier, h.GetPaymentRefunds)
+	
+	// Shift routes
+	staff.Get("/shifts/current", h.GetCurrentShift)
+	staff.Post("/shifts/start", h.StartShift)
+	staff.Post("/shifts/end", h.EndShift)
 	
 	// Notification routes
 	staff.Get("/notifications", h.GetNotifications)
@@ -68,11 +149,12 @@ func SetupRoutes(app *fiber.App, h *handlers.Handlers) {
 	staff.Put("/notifications/read-all", h.MarkAllAsRead)
 	
 	// Analytics routes
-	staff.Get("/analytics/sales", h.GetSalesAnalytics)
-	staff.Get("/analytics/tables", h.GetTableAnalytics)
-	staff.Get("/analytics/menu", h.GetMenuPerformance)
+	staff.Get("/analytics/sales", adminOnly, h.GetSalesAnalytics)
+	staff.Get("/analytics/tables", adminOnly, h.GetTableAnalytics)
+	staff.Get("/analytics/menu", adminOnly, h.GetMenuPerformance)
+	staff.Get("/analytics/tips", adminOnly, h.GetTipAnalytics)
 
 	// Webhook routes
 	webhook := api.Group("/webhook")
-	webhook.Post("/midtrans", h.HandleMidtransNotification)
+	webhook.Post("/midtrans", h.HandlePaymentNotification)
 }
\ No newline at end of file
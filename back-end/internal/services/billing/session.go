@@ -0,0 +1,167 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/payment"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNothingToPay = errors.New("session has no unpaid orders")
+
+// UnpaidOrders returns the session's orders that can still go on a session
+// bill: not cancelled, not paid or being paid, and not split.
+func UnpaidOrders(db *gorm.DB, sessionID uint) ([]models.Order, error) {
+	var orders []models.Order
+	err := unpaidOrdersQuery(db, sessionID).
+		Preload("OrderItems.MenuItem").
+		Order("created_at").
+		Find(&orders).Error
+	return orders, err
+}
+
+// CreateSessionBill puts every unpaid order of the session on one bill and
+// creates a pending payment per order under the bill's reference. The tip
+// is worked out on the combined subtotal and spread over the payments.
+func CreateSessionBill(tx *gorm.DB, sessionID uint, provider string, tip TipRequest) (*models.SessionBill, []models.Order, error) {
+	var orders []models.Order
+	err := unpaidOrdersQuery(tx, sessionID).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("OrderItems.MenuItem").
+		Order("created_at").
+		Find(&orders).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil, ErrNothingToPay
+	}
+
+	var subtotal models.Money
+	subtotals := make([]models.Money, len(orders))
+	for i, order := range orders {
+		subtotal += order.TotalAmount
+		subtotals[i] = order.TotalAmount
+	}
+	tipAmount, err := tip.Resolve(subtotal)
+	if err != nil {
+		return nil, nil, err
+	}
+	tips := tipAmount.Allocate(subtotals)
+
+	bill := models.SessionBill{
+		SessionID: sessionID,
+		Reference: fmt.Sprintf("BILL-%d-%d", sessionID, time.Now().UnixNano()),
+		Status:    models.PaymentStatusPending,
+		TipAmount: tipAmount,
+	}
+	for _, order := range orders {
+		bill.TotalAmount += order.GrandTotal
+	}
+	bill.TotalAmount += tipAmount
+	if err := tx.Create(&bill).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for i, order := range orders {
+		record := models.Payment{
+			OrderID:         order.ID,
+			SessionBillID:   &bill.ID,
+			Provider:        provider,
+			Method:          models.PaymentMethodGateway,
+			Status:          models.PaymentStatusPending,
+			MidtransOrderID: fmt.Sprintf("%s-%s", bill.Reference, order.OrderNumber),
+			GrossAmount:     order.GrandTotal + tips[i],
+			TipAmount:       tips[i],
+			Currency:        "IDR",
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, nil, err
+		}
+		bill.Payments = append(bill.Payments, record)
+	}
+
+	orderIDs := make([]uint, len(orders))
+	for i, order := range orders {
+		orderIDs[i] = order.ID
+	}
+	err = tx.Model(&models.Order{}).Where("id IN ?", orderIDs).Update("payment_status", models.PaymentStatusPending).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &bill, orders, nil
+}
+
+// FailSessionBill releases the orders of a bill whose checkout could not be
+// started so they can be paid again.
+func FailSessionBill(tx *gorm.DB, billID uint) error {
+	if err := tx.Model(&models.SessionBill{}).Where("id = ?", billID).Update("status", models.PaymentStatusFailed).Error; err != nil {
+		return err
+	}
+
+	orderIDs := tx.Model(&models.Payment{}).Select("order_id").Where("session_bill_id = ?", billID)
+	if err := tx.Model(&models.Payment{}).Where("session_bill_id = ?", billID).Update("status", models.PaymentStatusFailed).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Order{}).Where("id IN (?)", orderIDs).Update("payment_status", models.PaymentStatusFailed).Error
+}
+
+// ReferencePayments returns the payments made under a provider reference:
+// a single payment, or one per order for a session bill. The bill is nil
+// for single payments.
+func ReferencePayments(db *gorm.DB, reference string) ([]models.Payment, *models.SessionBill, error) {
+	var record models.Payment
+	err := db.Where("midtrans_order_id = ?", reference).First(&record).Error
+	if err == nil {
+		return []models.Payment{record}, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	var bill models.SessionBill
+	if err := db.Preload("Payments").Where("reference = ?", reference).First(&bill).Error; err != nil {
+		return nil, nil, err
+	}
+	return bill.Payments, &bill, nil
+}
+
+// SessionLineItems lists the items of every order on a session bill with
+// their tax and service charge combined, adding up to the bill total.
+func SessionLineItems(orders []models.Order) []payment.LineItem {
+	var items []payment.LineItem
+	var tax, service models.Money
+
+	for i := range orders {
+		for _, item := range payment.OrderLineItems(&orders[i]) {
+			switch item.ID {
+			case payment.LineItemTax:
+				tax += item.Price
+			case payment.LineItemService:
+				service += item.Price
+			default:
+				items = append(items, item)
+			}
+		}
+	}
+
+	if tax > 0 {
+		items = append(items, payment.LineItem{ID: payment.LineItemTax, Name: "Tax", Price: tax, Quantity: 1})
+	}
+	if service > 0 {
+		items = append(items, payment.LineItem{ID: payment.LineItemService, Name: "Service Charge", Price: service, Quantity: 1})
+	}
+	return items
+}
+
+func unpaidOrdersQuery(db *gorm.DB, sessionID uint) *gorm.DB {
+	return db.Model(&models.Order{}).
+		Where("session_id = ? AND status <> ? AND payment_status IN ?", sessionID, models.OrderStatusCancelled,
+			[]string{models.PaymentStatusUnpaid, models.PaymentStatusFailed}).
+		Where("NOT EXISTS (SELECT 1 FROM split_bills WHERE split_bills.order_id = orders.id AND split_bills.status = ?)", models.SplitBillStatusOpen)
+}
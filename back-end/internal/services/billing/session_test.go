@@ -0,0 +1,78 @@
+package billing
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/payment"
+	"lendral3n/ordering-system/internal/services/pricing"
+	"testing"
+)
+
+func TestSessionLineItemsAddUpToBillTotal(t *testing.T) {
+	pb1 := models.TaxRule{Name: "PB1", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePB1, Rate: 10}
+	ppn := models.TaxRule{Name: "PPN", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePPN, Rate: 11, Inclusive: true}
+	service := models.TaxRule{Name: "Service", Kind: models.TaxRuleKindService, Rate: 5.5, DineInOnly: true}
+
+	order := func(orderType string, items ...models.OrderItem) models.Order {
+		return models.Order{OrderType: orderType, OrderItems: items}
+	}
+	soup := models.OrderItem{MenuItemID: 1, Quantity: 3, UnitPrice: 18333, Subtotal: 54999}
+	water := models.OrderItem{MenuItemID: 2, Quantity: 1, UnitPrice: 7777, Subtotal: 7777, TaxExempt: true}
+	cake := models.OrderItem{MenuItemID: 3, Quantity: 1, UnitPrice: 23456, Subtotal: 23456}
+	cancelled := models.OrderItem{MenuItemID: 4, Quantity: 1, UnitPrice: 45000, Subtotal: 45000, Status: models.OrderItemStatusCancelled}
+
+	tests := []struct {
+		name   string
+		rules  []models.TaxRule
+		orders []models.Order
+		tip    models.Money
+	}{
+		{"single order", []models.TaxRule{pb1, service}, []models.Order{order(models.OrderTypeDineIn, soup, water)}, 0},
+		{"charges rounded per order", []models.TaxRule{pb1, service}, []models.Order{
+			order(models.OrderTypeDineIn, soup),
+			order(models.OrderTypeDineIn, water, cake),
+			order(models.OrderTypeDineIn, cake),
+		}, 0},
+		{"dine in and takeaway", []models.TaxRule{pb1, service}, []models.Order{
+			order(models.OrderTypeDineIn, soup, water),
+			order(models.OrderTypeTakeaway, cake),
+		}, 0},
+		{"inclusive tax", []models.TaxRule{ppn, service}, []models.Order{
+			order(models.OrderTypeDineIn, soup),
+			order(models.OrderTypeDineIn, cake),
+		}, 0},
+		{"cancelled items", []models.TaxRule{pb1, service}, []models.Order{
+			order(models.OrderTypeDineIn, soup, cancelled),
+			order(models.OrderTypeDineIn, cake),
+		}, 0},
+		{"tip", []models.TaxRule{pb1, service}, []models.Order{
+			order(models.OrderTypeDineIn, soup),
+			order(models.OrderTypeDineIn, cake),
+		}, 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Bill total as CreateSessionBill adds it up
+			total := tt.tip
+			for i := range tt.orders {
+				pricing.Apply(&tt.orders[i], tt.rules)
+				total += tt.orders[i].GrandTotal
+			}
+
+			lines := payment.WithTip(SessionLineItems(tt.orders), tt.tip)
+			if got := payment.LineItemsTotal(lines); got != total {
+				t.Errorf("lines add up to %d, want %d: %+v", got, total, lines)
+			}
+
+			charges := 0
+			for _, line := range lines {
+				if line.ID == payment.LineItemTax || line.ID == payment.LineItemService {
+					charges++
+				}
+			}
+			if charges > 2 {
+				t.Errorf("got %d tax and service lines, want them combined", charges)
+			}
+		})
+	}
+}
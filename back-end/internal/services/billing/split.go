@@ -0,0 +1,400 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/payment"
+	"lendral3n/ordering-system/internal/services/pricing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxShares caps how many ways a bill can be split.
+const MaxShares = 20
+
+var (
+	ErrSplitExists       = errors.New("order already has an open split bill")
+	ErrNoSplit           = errors.New("order has no open split bill")
+	ErrSplitInProgress   = errors.New("split bill already has payments")
+	ErrOrderNotSplitable = errors.New("only unpaid orders can be split")
+	ErrInvalidSplit      = errors.New("invalid split")
+	ErrShareNotPayable   = errors.New("share is already paid or being paid")
+)
+
+// SplitRequest describes how to divide an order. Only the field matching
+// Mode is used.
+type SplitRequest struct {
+	Mode    string
+	Shares  int            // equal: number of shares
+	Amounts []models.Money // custom: amount per share
+	Items   [][]uint       // item: order item IDs per share
+	Labels  []string       // optional, one per share
+}
+
+// CreateSplit divides an unpaid order into shares. Amounts are whole rupiah
+// and always add up to the order's grand total; when splitting by item, each
+// share pays the tax and service charge on its own items.
+func CreateSplit(tx *gorm.DB, orderID uint, req SplitRequest) (*models.SplitBill, error) {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("OrderItems").Preload("Charges").First(&order, orderID).Error; err != nil {
+		return nil, err
+	}
+
+	if order.Status == models.OrderStatusCancelled || (order.PaymentStatus != models.PaymentStatusUnpaid && order.PaymentStatus != models.PaymentStatusFailed) {
+		return nil, ErrOrderNotSplitable
+	}
+
+	var open int64
+	if err := tx.Model(&models.SplitBill{}).Where("order_id = ? AND status = ?", order.ID, models.SplitBillStatusOpen).Count(&open).Error; err != nil {
+		return nil, err
+	}
+	if open > 0 {
+		return nil, ErrSplitExists
+	}
+
+	total := order.GrandTotal
+	var amounts []models.Money
+	var items [][]uint
+
+	switch req.Mode {
+	case models.SplitModeEqual:
+		if req.Shares < 2 || req.Shares > MaxShares {
+			return nil, fmt.Errorf("%w: shares must be between 2 and %d", ErrInvalidSplit, MaxShares)
+		}
+		amounts = total.Allocate(make([]models.Money, req.Shares))
+
+	case models.SplitModeCustom:
+		var err error
+		if amounts, err = customShares(total, req.Amounts); err != nil {
+			return nil, err
+		}
+
+	case models.SplitModeItem:
+		var err error
+		if amounts, err = itemShares(&order, req.Items); err != nil {
+			return nil, err
+		}
+		items = req.Items
+
+	default:
+		return nil, fmt.Errorf("%w: mode must be item, equal or custom", ErrInvalidSplit)
+	}
+
+	bill := models.SplitBill{
+		OrderID:     order.ID,
+		Mode:        req.Mode,
+		Status:      models.SplitBillStatusOpen,
+		TotalAmount: total,
+	}
+	for i, amount := range amounts {
+		share := models.SplitShare{
+			Label:  fmt.Sprintf("Share %d", i+1),
+			Amount: amount,
+			Status: models.PaymentStatusUnpaid,
+		}
+		if i < len(req.Labels) && req.Labels[i] != "" {
+			share.Label = req.Labels[i]
+		}
+		if items != nil {
+			for _, itemID := range items[i] {
+				share.Items = append(share.Items, models.SplitShareItem{OrderItemID: itemID})
+			}
+		}
+		bill.Shares = append(bill.Shares, share)
+	}
+
+	if err := tx.Create(&bill).Error; err != nil {
+		return nil, err
+	}
+
+	return &bill, nil
+}
+
+// GetSplit returns the order's current split bill, open or settled, with
+// its shares and their items.
+func GetSplit(db *gorm.DB, orderID uint) (*models.SplitBill, error) {
+	var bill models.SplitBill
+	err := db.Preload("Shares", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).
+		Preload("Shares.Items.OrderItem.MenuItem").
+		Preload("Shares.Payment").
+		Where("order_id = ? AND status <> ?", orderID, models.SplitBillStatusCancelled).
+		Order("created_at DESC").
+		First(&bill).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoSplit
+		}
+		return nil, err
+	}
+	return &bill, nil
+}
+
+// HasOpenSplit reports whether the order is being paid in shares.
+func HasOpenSplit(db *gorm.DB, orderID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.SplitBill{}).Where("order_id = ? AND status = ?", orderID, models.SplitBillStatusOpen).Count(&count).Error
+	return count > 0, err
+}
+
+// CancelSplit drops the order's open split bill so it can be paid in full
+// or split differently. Bills with shares paid or in payment are kept.
+func CancelSplit(tx *gorm.DB, orderID uint) error {
+	var bill models.SplitBill
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, models.SplitBillStatusOpen).
+		First(&bill).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoSplit
+		}
+		return err
+	}
+
+	var started int64
+	if err := tx.Model(&models.SplitShare{}).Where("split_bill_id = ? AND status <> ?", bill.ID, models.PaymentStatusUnpaid).Count(&started).Error; err != nil {
+		return err
+	}
+	if started > 0 {
+		return ErrSplitInProgress
+	}
+
+	return tx.Model(&bill).Update("status", models.SplitBillStatusCancelled).Error
+}
+
+// VoidSplit cancels any open split bill of an order that is itself being
+// cancelled, whatever state its shares are in.
+func VoidSplit(tx *gorm.DB, orderID uint) error {
+	return tx.Model(&models.SplitBill{}).
+		Where("order_id = ? AND status = ?", orderID, models.SplitBillStatusOpen).
+		Update("status", models.SplitBillStatusCancelled).Error
+}
+
+// LockShare loads a share of the order's open split bill for payment. The
+// share must not be paid or already in payment.
+func LockShare(tx *gorm.DB, orderID, shareID uint) (*models.SplitShare, error) {
+	var share models.SplitShare
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Joins("JOIN split_bills ON split_bills.id = split_shares.split_bill_id").
+		Where("split_shares.id = ? AND split_bills.order_id = ? AND split_bills.status = ?", shareID, orderID, models.SplitBillStatusOpen).
+		Preload("Items.OrderItem.MenuItem").
+		First(&share).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if share.Status != models.PaymentStatusUnpaid {
+		return nil, ErrShareNotPayable
+	}
+	return &share, nil
+}
+
+// UpdateShare moves a share along with the status of one of its payments
+// and reports whether every share of the bill is now paid, in which case the
+// bill is settled. Failed payments return the share to unpaid so it can be
+// paid again; updates from superseded attempts only count when they settle.
+func UpdateShare(tx *gorm.DB, shareID, paymentID uint, paymentStatus string) (bool, error) {
+	var share models.SplitShare
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&share, shareID).Error; err != nil {
+		return false, err
+	}
+
+	if paymentStatus != models.PaymentStatusPaid && share.PaymentID != nil && *share.PaymentID != paymentID {
+		return false, nil
+	}
+
+	status := share.Status
+	switch paymentStatus {
+	case models.PaymentStatusPaid:
+		status = models.PaymentStatusPaid
+		if err := tx.Model(&share).Update("payment_id", paymentID).Error; err != nil {
+			return false, err
+		}
+	case models.PaymentStatusPending:
+		if share.Status != models.PaymentStatusPaid {
+			status = models.PaymentStatusPending
+		}
+	case models.PaymentStatusFailed:
+		if share.Status != models.PaymentStatusPaid {
+			status = models.PaymentStatusUnpaid
+		}
+	}
+	if status != share.Status {
+		if err := tx.Model(&share).Update("status", status).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if status != models.PaymentStatusPaid {
+		return false, nil
+	}
+
+	var unpaid int64
+	if err := tx.Model(&models.SplitShare{}).Where("split_bill_id = ? AND status <> ?", share.SplitBillID, models.PaymentStatusPaid).Count(&unpaid).Error; err != nil {
+		return false, err
+	}
+	if unpaid > 0 {
+		return false, nil
+	}
+
+	result := tx.Model(&models.SplitBill{}).
+		Where("id = ? AND status = ?", share.SplitBillID, models.SplitBillStatusOpen).
+		Update("status", models.SplitBillStatusSettled)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ShareLineItems lists what a share pays for. Shares split by item show
+// their items plus the tax and service charge on them; other shares are a
+// single line.
+func ShareLineItems(order *models.Order, share *models.SplitShare) []payment.LineItem {
+	items := make([]payment.LineItem, 0, len(share.Items)+1)
+	var subtotal models.Money
+	for _, shareItem := range share.Items {
+		if shareItem.OrderItem == nil {
+			continue
+		}
+		item := shareItem.OrderItem
+		items = append(items, payment.LineItem{
+			ID:       fmt.Sprintf("ITEM-%d", item.MenuItemID),
+			Name:     item.MenuItem.Name,
+			Price:    item.UnitPrice,
+			Quantity: item.Quantity,
+		})
+		subtotal += item.UnitPrice.Times(item.Quantity)
+	}
+
+	extra := share.Amount - subtotal
+	if len(items) == 0 || extra < 0 {
+		return []payment.LineItem{{
+			ID:       fmt.Sprintf("SHARE-%d", share.ID),
+			Name:     fmt.Sprintf("%s, order #%s", share.Label, order.OrderNumber),
+			Price:    share.Amount,
+			Quantity: 1,
+		}}
+	}
+
+	if extra > 0 {
+		items = append(items, payment.LineItem{
+			ID:       "TAX-SERVICE",
+			Name:     "Tax & Service Charge",
+			Price:    extra,
+			Quantity: 1,
+		})
+	}
+	return items
+}
+
+func customShares(total models.Money, requested []models.Money) ([]models.Money, error) {
+	if len(requested) < 2 || len(requested) > MaxShares {
+		return nil, fmt.Errorf("%w: shares must be between 2 and %d", ErrInvalidSplit, MaxShares)
+	}
+
+	var sum models.Money
+	for _, amount := range requested {
+		if amount <= 0 {
+			return nil, fmt.Errorf("%w: share amounts must be positive", ErrInvalidSplit)
+		}
+		sum += amount
+	}
+
+	if sum != total {
+		return nil, fmt.Errorf("%w: shares add up to %d, order total is %d", ErrInvalidSplit, sum, total)
+	}
+	return requested, nil
+}
+
+// itemShares works out what each share of an item split pays: its items
+// plus its part of every charge added to the order, spread over the items
+// the charge was worked out on. A share of tax exempt items pays no tax, and
+// charges the order was not priced with, such as a dine-in service charge
+// on a takeaway order, are not there to spread. Inclusive taxes are already
+// in the item prices.
+func itemShares(order *models.Order, groups [][]uint) ([]models.Money, error) {
+	shareItems, err := assignItems(order.OrderItems, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	// Orders priced before charges were recorded only have their totals
+	charges := order.Charges
+	if len(charges) == 0 {
+		charges = []models.OrderCharge{
+			{Kind: models.TaxRuleKindTax, Amount: order.TaxAmount},
+			{Kind: models.TaxRuleKindService, Amount: order.ServiceCharge},
+		}
+	}
+
+	amounts := make([]models.Money, len(shareItems))
+	for i, items := range shareItems {
+		for _, item := range items {
+			amounts[i] += item.Subtotal
+		}
+	}
+
+	for _, charge := range charges {
+		if charge.Inclusive || charge.Amount == 0 {
+			continue
+		}
+		bases := make([]models.Money, len(shareItems))
+		for i, items := range shareItems {
+			for _, item := range items {
+				bases[i] += pricing.ItemBase(charge.Kind, item)
+			}
+		}
+		for i, part := range charge.Amount.Allocate(bases) {
+			amounts[i] += part
+		}
+	}
+
+	// Totals edited outside the pricing rules still split in full
+	var sum models.Money
+	for _, amount := range amounts {
+		sum += amount
+	}
+	if sum != order.GrandTotal {
+		amounts = order.GrandTotal.Allocate(amounts)
+	}
+	return amounts, nil
+}
+
+// assignItems checks that every active order item is assigned to exactly
+// one share and returns the items of each share.
+func assignItems(orderItems []models.OrderItem, groups [][]uint) ([][]models.OrderItem, error) {
+	if len(groups) < 2 || len(groups) > MaxShares {
+		return nil, fmt.Errorf("%w: shares must be between 2 and %d", ErrInvalidSplit, MaxShares)
+	}
+
+	active := make(map[uint]models.OrderItem, len(orderItems))
+	for _, item := range orderItems {
+		if item.Status != models.OrderItemStatusCancelled {
+			active[item.ID] = item
+		}
+	}
+
+	assigned := make(map[uint]bool, len(active))
+	shares := make([][]models.OrderItem, len(groups))
+	for i, group := range groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("%w: every share needs at least one item", ErrInvalidSplit)
+		}
+		for _, itemID := range group {
+			item, ok := active[itemID]
+			if !ok {
+				return nil, fmt.Errorf("%w: item %d is not part of the order", ErrInvalidSplit, itemID)
+			}
+			if assigned[itemID] {
+				return nil, fmt.Errorf("%w: item %d is assigned twice", ErrInvalidSplit, itemID)
+			}
+			assigned[itemID] = true
+			shares[i] = append(shares[i], item)
+		}
+	}
+
+	if len(assigned) != len(active) {
+		return nil, fmt.Errorf("%w: every item must be assigned to a share", ErrInvalidSplit)
+	}
+	return shares, nil
+}
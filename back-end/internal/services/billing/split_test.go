@@ -0,0 +1,123 @@
+package billing
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/pricing"
+	"testing"
+)
+
+func TestItemShares(t *testing.T) {
+	pb1 := models.TaxRule{Name: "PB1", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePB1, Rate: 10}
+	ppn := models.TaxRule{Name: "PPN", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePPN, Rate: 11, Inclusive: true}
+	service := models.TaxRule{Name: "Service", Kind: models.TaxRuleKindService, Rate: 5, DineInOnly: true}
+
+	// A dish, a tax exempt bottle of water and a cancelled dessert
+	items := func() []models.OrderItem {
+		return []models.OrderItem{
+			{ID: 1, Quantity: 2, UnitPrice: 25000, Subtotal: 50000},
+			{ID: 2, Quantity: 1, UnitPrice: 20000, Subtotal: 20000, TaxExempt: true},
+			{ID: 3, Quantity: 1, UnitPrice: 15000, Subtotal: 15000, Status: models.OrderItemStatusCancelled},
+		}
+	}
+	groups := [][]uint{{1}, {2}}
+
+	tests := []struct {
+		name      string
+		orderType string
+		rules     []models.TaxRule
+		want      []models.Money
+	}{
+		// Dish: 50000 + 5000 tax + 2500 service; water: 20000 + 1000 service
+		{"exempt share pays no tax", models.OrderTypeDineIn, []models.TaxRule{pb1, service}, []models.Money{57500, 21000}},
+		{"takeaway pays no dine in service", models.OrderTypeTakeaway, []models.TaxRule{pb1, service}, []models.Money{55000, 20000}},
+		{"inclusive tax is already in the prices", models.OrderTypeDineIn, []models.TaxRule{ppn, service}, []models.Money{52500, 21000}},
+		{"no charges", models.OrderTypeDineIn, nil, []models.Money{50000, 20000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := models.Order{OrderType: tt.orderType, OrderItems: items()}
+			pricing.Apply(&order, tt.rules)
+
+			amounts, err := itemShares(&order, groups)
+			if err != nil {
+				t.Fatalf("itemShares: %v", err)
+			}
+
+			var sum models.Money
+			for i := range amounts {
+				if amounts[i] != tt.want[i] {
+					t.Errorf("share %d = %d, want %d", i+1, amounts[i], tt.want[i])
+				}
+				sum += amounts[i]
+			}
+			if sum != order.GrandTotal {
+				t.Errorf("shares add up to %d, want %d", sum, order.GrandTotal)
+			}
+		})
+	}
+}
+
+func TestItemSharesRoundsEachCharge(t *testing.T) {
+	order := models.Order{OrderType: models.OrderTypeDineIn, OrderItems: []models.OrderItem{
+		{ID: 1, Quantity: 1, UnitPrice: 3333, Subtotal: 3333},
+		{ID: 2, Quantity: 1, UnitPrice: 3333, Subtotal: 3333},
+		{ID: 3, Quantity: 1, UnitPrice: 3333, Subtotal: 3333},
+	}}
+	pricing.Apply(&order, []models.TaxRule{
+		{Name: "PB1", Kind: models.TaxRuleKindTax, Rate: 10},
+		{Name: "Service", Kind: models.TaxRuleKindService, Rate: 5.5},
+	})
+
+	amounts, err := itemShares(&order, [][]uint{{1}, {2}, {3}})
+	if err != nil {
+		t.Fatalf("itemShares: %v", err)
+	}
+
+	var sum models.Money
+	for _, amount := range amounts {
+		sum += amount
+	}
+	if sum != order.GrandTotal {
+		t.Errorf("shares add up to %d, want %d", sum, order.GrandTotal)
+	}
+
+	// Each share owns its items; the charges' rounding goes to the last
+	for _, amount := range amounts[:2] {
+		if amount != 3333+333+183 {
+			t.Errorf("share = %d, want %d", amount, 3333+333+183)
+		}
+	}
+}
+
+func TestAssignItems(t *testing.T) {
+	items := []models.OrderItem{
+		{ID: 1, Subtotal: 1000},
+		{ID: 2, Subtotal: 2000},
+		{ID: 3, Subtotal: 3000, Status: models.OrderItemStatusCancelled},
+		{ID: 4, Subtotal: 4000},
+	}
+
+	tests := []struct {
+		name   string
+		groups [][]uint
+		ok     bool
+	}{
+		{"every item once", [][]uint{{1, 4}, {2}}, true},
+		{"one share", [][]uint{{1, 2, 4}}, false},
+		{"empty share", [][]uint{{1, 2, 4}, {}}, false},
+		{"item left out", [][]uint{{1}, {2}}, false},
+		{"item assigned twice", [][]uint{{1, 4}, {2, 4}}, false},
+		{"cancelled item", [][]uint{{1, 4}, {2, 3}}, false},
+		{"unknown item", [][]uint{{1, 4}, {2, 9}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := assignItems(items, tt.groups)
+			if (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
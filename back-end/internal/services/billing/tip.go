@@ -0,0 +1,37 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"slices"
+)
+
+var ErrInvalidTip = errors.New("invalid tip")
+
+// TipRequest is the tip picked at checkout: one of the offered percentages
+// of the subtotal, or a custom amount. Neither means no tip.
+type TipRequest struct {
+	Percent float64
+	Amount  models.Money
+	Presets []float64 // percentages on offer
+}
+
+// Resolve works out the tip in whole rupiah for a bill with the given
+// subtotal.
+func (r TipRequest) Resolve(subtotal models.Money) (models.Money, error) {
+	switch {
+	case r.Percent != 0 && r.Amount != 0:
+		return 0, fmt.Errorf("%w: choose a percentage or an amount, not both", ErrInvalidTip)
+	case r.Percent != 0:
+		if !slices.Contains(r.Presets, r.Percent) {
+			return 0, fmt.Errorf("%w: %v%% is not a tip option", ErrInvalidTip, r.Percent)
+		}
+		return subtotal.Percent(r.Percent), nil
+	case r.Amount < 0:
+		return 0, fmt.Errorf("%w: tip cannot be negative", ErrInvalidTip)
+	case r.Amount > subtotal:
+		return 0, fmt.Errorf("%w: tip cannot exceed the subtotal", ErrInvalidTip)
+	}
+	return r.Amount, nil
+}
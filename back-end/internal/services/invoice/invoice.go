@@ -1,227 +1,251 @@
 package invoice
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"html/template"
 	"lendral3n/ordering-system/internal/models"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/SebastiaanKlippert/go-wkhtmltopdf"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Service struct {
-	db *gorm.DB
+	db       *gorm.DB
+	renderer Renderer
 }
 
-func NewService(db *gorm.DB) *Service {
+func NewService(db *gorm.DB, renderer Renderer) *Service {
 	return &Service{
-		db: db,
+		db:       db,
+		renderer: renderer,
 	}
 }
 
 type InvoiceData struct {
-	InvoiceNumber string
-	InvoiceDate   string
-	Order         *models.Order
-	Payment       *models.Payment
-	Items         []InvoiceItem
-	Subtotal      float64
-	Tax           float64
-	ServiceCharge float64
-	Total         float64
+	InvoiceNumber      string
+	InvoiceDate        string
+	Restaurant         models.RestaurantProfile
+	Order              *models.Order
+	Payment            *models.Payment
+	Items              []InvoiceItem
+	Subtotal           models.Money
+	Tax                models.Money
+	TaxLabel           string // e.g. "PB1 (10%)"
+	ServiceCharge      models.Money
+	ServiceChargeLabel string // e.g. "Service Charge (5%)"
+	Tip                models.Money
+	Total              models.Money // tip included
+	IncludedTax        models.Money // already in the item prices
+	IncludedTaxLabel   string       // e.g. "Includes PPN (11%)"
 }
 
 type InvoiceItem struct {
 	Name      string
 	Quantity  int
-	UnitPrice float64
-	Total     float64
+	UnitPrice models.Money
+	Total     models.Money
 }
 
-func (s *Service) GenerateInvoice(orderID uint) ([]byte, string, error) {
-	// Get order with items
+var ErrOrderNotPaid = errors.New("order is not paid")
+
+// GetOrCreateInvoice returns the invoice for a paid order, issuing a new
+// invoice number only the first time it is requested.
+func (s *Service) GetOrCreateInvoice(orderID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := s.db.Where("order_id = ?", orderID).First(&invoice).Error
+	if err == nil {
+		return &invoice, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	
 	var order models.Order
-	err := s.db.Preload("OrderItems.MenuItem").Preload("Table").First(&order, orderID).Error
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to get order: %w", err)
+	if err := s.db.First(&order, orderID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 	
-	// Get payment
-	var payment models.Payment
-	err = s.db.Where("order_id = ?", orderID).First(&payment).Error
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to get payment: %w", err)
+	if order.PaymentStatus != models.PaymentStatusPaid {
+		return nil, ErrOrderNotPaid
 	}
 	
-	// Generate invoice number
-	invoiceNumber := s.generateInvoiceNumber()
+	invoice = models.Invoice{
+		InvoiceNumber: s.generateInvoiceNumber(),
+		OrderID:       orderID,
+		IssuedDate:    time.Now(),
+	}
 	
-	// Prepare invoice data
-	invoiceData := InvoiceData{
-		InvoiceNumber: invoiceNumber,
-		InvoiceDate:   time.Now().Format("02 January 2006"),
-		Order:         &order,
-		Payment:       &payment,
-		Items:         make([]InvoiceItem, 0, len(order.OrderItems)),
-		Subtotal:      order.TotalAmount,
-		Tax:           order.TaxAmount,
-		ServiceCharge: order.ServiceCharge,
-		Total:         order.GrandTotal,
+	// The unique index on order_id makes concurrent requests race safely:
+	// the loser simply picks up the winner's invoice.
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&invoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
 	}
 	
-	for _, item := range order.OrderItems {
-		invoiceData.Items = append(invoiceData.Items, InvoiceItem{
-			Name:      item.MenuItem.Name,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
-			Total:     item.Subtotal,
-		})
+	if invoice.ID == 0 {
+		if err := s.db.Where("order_id = ?", orderID).First(&invoice).Error; err != nil {
+			return nil, fmt.Errorf("failed to get invoice: %w", err)
+		}
+	}
+	
+	return &invoice, nil
+}
+
+// GenerateInvoice renders the invoice PDF for a paid order. Repeat calls
+// render the same persisted invoice.
+func (s *Service) GenerateInvoice(orderID uint) ([]byte, *models.Invoice, error) {
+	invoice, err := s.GetOrCreateInvoice(orderID)
+	if err != nil {
+		return nil, nil, err
 	}
 	
-	// Generate HTML
-	html, err := s.generateHTML(invoiceData)
+	invoiceData, err := s.buildInvoiceData(invoice)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate HTML: %w", err)
+		return nil, nil, err
 	}
 	
-	// Convert to PDF
-	pdf, err := s.generatePDF(html)
+	// Render PDF
+	pdf, err := s.renderer.Render(*invoiceData)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate PDF: %w", err)
+		return nil, nil, fmt.Errorf("failed to render invoice: %w", err)
 	}
 	
-	return pdf, invoiceNumber, nil
+	return pdf, invoice, nil
 }
 
-func (s *Service) generateHTML(data InvoiceData) (string, error) {
-	tmplStr := `
-<!DOCTYPE html>
-<html>
-<head>
-    <style>
-        body { font-family: Arial, sans-serif; margin: 40px; }
-        .header { text-align: center; margin-bottom: 30px; }
-        .invoice-info { margin-bottom: 20px; }
-        .invoice-info div { margin: 5px 0; }
-        table { width: 100%; border-collapse: collapse; margin: 20px 0; }
-        th, td { border: 1px solid #ddd; padding: 12px; text-align: left; }
-        th { background-color: #f8f9fa; font-weight: bold; }
-        .text-right { text-align: right; }
-        .totals { margin-top: 20px; }
-        .totals table { width: 300px; margin-left: auto; }
-        .totals td { border: none; padding: 8px; }
-        .grand-total { font-size: 1.2em; font-weight: bold; }
-        .footer { margin-top: 40px; text-align: center; color: #666; }
-    </style>
-</head>
-<body>
-    <div class="header">
-        <h1>INVOICE</h1>
-        <h2>Restaurant Name</h2>
-        <p>Jl. Example Street No. 123<br>Jakarta, Indonesia</p>
-    </div>
-    
-    <div class="invoice-info">
-        <div><strong>Invoice Number:</strong> {{.InvoiceNumber}}</div>
-        <div><strong>Date:</strong> {{.InvoiceDate}}</div>
-        <div><strong>Order Number:</strong> {{.Order.OrderNumber}}</div>
-        <div><strong>Table:</strong> {{.Order.Table.TableNumber}}</div>
-        <div><strong>Payment Method:</strong> {{if .Payment.PaymentType}}{{.Payment.PaymentType}}{{else}}N/A{{end}}</div>
-    </div>
-    
-    <table>
-        <thead>
-            <tr>
-                <th>Item</th>
-                <th class="text-right">Qty</th>
-                <th class="text-right">Unit Price</th>
-                <th class="text-right">Total</th>
-            </tr>
-        </thead>
-        <tbody>
-            {{range .Items}}
-            <tr>
-                <td>{{.Name}}</td>
-                <td class="text-right">{{.Quantity}}</td>
-                <td class="text-right">Rp {{printf "%.0f" .UnitPrice}}</td>
-                <td class="text-right">Rp {{printf "%.0f" .Total}}</td>
-            </tr>
-            {{end}}
-        </tbody>
-    </table>
-    
-    <div class="totals">
-        <table>
-            <tr>
-                <td>Subtotal:</td>
-                <td class="text-right">Rp {{printf "%.0f" .Subtotal}}</td>
-            </tr>
-            <tr>
-                <td>Tax (10%):</td>
-                <td class="text-right">Rp {{printf "%.0f" .Tax}}</td>
-            </tr>
-            <tr>
-                <td>Service Charge (5%):</td>
-                <td class="text-right">Rp {{printf "%.0f" .ServiceCharge}}</td>
-            </tr>
-            <tr class="grand-total">
-                <td>Total:</td>
-                <td class="text-right">Rp {{printf "%.0f" .Total}}</td>
-            </tr>
-        </table>
-    </div>
-    
-    <div class="footer">
-        <p>Thank you for dining with us!</p>
-        <p>Please visit us again</p>
-    </div>
-</body>
-</html>
-`
-	
-	tmpl, err := template.New("invoice").Parse(tmplStr)
+func (s *Service) buildInvoiceData(invoice *models.Invoice) (*InvoiceData, error) {
+	// Get order with items
+	var order models.Order
+	err := s.db.Preload("OrderItems.MenuItem").Preload("Table").Preload("Charges").First(&order, invoice.OrderID).Error
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 	
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+	// Get payment
+	var payment models.Payment
+	err = s.db.Where("order_id = ?", invoice.OrderID).Order("created_at DESC").First(&payment).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 	
-	return buf.String(), nil
-}
-
-func (s *Service) generatePDF(html string) ([]byte, error) {
-	pdfg, err := wkhtmltopdf.NewPDFGenerator()
+	// Tips are paid on top of the order total
+	var tip models.Money
+	err = s.db.Model(&models.Payment{}).
+		Select("COALESCE(SUM(tip_amount), 0)").
+		Where("order_id = ? AND status IN ?", invoice.OrderID, []string{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded}).
+		Scan(&tip).Error
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get tips: %w", err)
 	}
 	
-	// Set global options
-	pdfg.Dpi.Set(300)
-	pdfg.Orientation.Set(wkhtmltopdf.OrientationPortrait)
-	pdfg.PageSize.Set(wkhtmltopdf.PageSizeA4)
+	restaurant := s.GetRestaurantProfile()
 	
-	// Create page from HTML
-	page := wkhtmltopdf.NewPageReader(bytes.NewReader([]byte(html)))
-	pdfg.AddPage(page)
+	// Prepare invoice data
+	invoiceData := &InvoiceData{
+		InvoiceNumber:      invoice.InvoiceNumber,
+		InvoiceDate:        invoice.IssuedDate.Format("02 January 2006"),
+		Restaurant:         restaurant,
+		Order:              &order,
+		Payment:            &payment,
+		Items:              make([]InvoiceItem, 0, len(order.OrderItems)),
+		Subtotal:           order.TotalAmount,
+		Tax:                order.TaxAmount,
+		TaxLabel:           chargesLabel(order.Charges, models.TaxRuleKindTax, false),
+		ServiceCharge:      order.ServiceCharge,
+		ServiceChargeLabel: chargesLabel(order.Charges, models.TaxRuleKindService, false),
+		Tip:                tip,
+		Total:              order.GrandTotal + tip,
+		IncludedTax:        order.IncludedTax,
+	}
 	
-	// Create PDF
-	if err := pdfg.Create(); err != nil {
-		return nil, err
+	// Orders priced without stored rules use the restaurant's labels
+	if invoiceData.TaxLabel == "" {
+		invoiceData.TaxLabel = rateLabel(restaurant.TaxLabel, order.EffectiveTaxRate())
+	}
+	if invoiceData.ServiceChargeLabel == "" {
+		invoiceData.ServiceChargeLabel = rateLabel(restaurant.ServiceChargeLabel, order.EffectiveServiceRate())
+	}
+	if order.IncludedTax > 0 {
+		invoiceData.IncludedTaxLabel = "Includes " + chargesLabel(order.Charges, models.TaxRuleKindTax, true)
 	}
 	
-	return pdfg.Bytes(), nil
+	for _, item := range order.OrderItems {
+		invoiceData.Items = append(invoiceData.Items, InvoiceItem{
+			Name:      item.MenuItem.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Total:     item.Subtotal,
+		})
+	}
+	
+	return invoiceData, nil
+}
+
+// GetRestaurantProfile returns the saved restaurant profile, or the
+// defaults when none has been configured yet.
+func (s *Service) GetRestaurantProfile() models.RestaurantProfile {
+	profile := models.DefaultRestaurantProfile()
+	s.db.First(&profile, models.RestaurantProfileID)
+	return profile
 }
 
 func (s *Service) generateInvoiceNumber() string {
-	// Format: INV-YYYYMMDD-XXXX
+	// Format: INV-YYYYMMDD-XXXXXXXX
+	// Invoice numbers are persisted with a unique index, so keep enough
+	// randomness to make same-day collisions negligible.
 	now := time.Now()
 	dateStr := now.Format("20060102")
-	uniqueID := uuid.New().String()[:4]
+	uniqueID := strings.ToUpper(uuid.New().String()[:8])
 	return fmt.Sprintf("INV-%s-%s", dateStr, uniqueID)
+}
+
+// rateLabel formats a label with its percentage, e.g. "PB1 (10%)".
+func rateLabel(label string, rate float64) string {
+	if rate <= 0 {
+		return label
+	}
+	return fmt.Sprintf("%s (%s%%)", label, strconv.FormatFloat(rate, 'f', -1, 64))
+}
+
+// chargesLabel names the order's stored-rule charges of one kind, e.g.
+// "PB1 (10%)" or "PB1 (10%), PPN (11%)". It is empty when there are none.
+func chargesLabel(charges []models.OrderCharge, kind string, inclusive bool) string {
+	var labels []string
+	for _, charge := range charges {
+		if charge.TaxRuleID != nil && charge.Kind == kind && charge.Inclusive == inclusive && charge.Amount > 0 {
+			labels = append(labels, rateLabel(charge.Name, charge.Rate))
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// formatRupiah formats an amount as "Rp 1.234.567".
+func formatRupiah(amount models.Money) string {
+	if amount < 0 {
+		return "-Rp " + formatAmount(-amount)
+	}
+	return "Rp " + formatAmount(amount)
+}
+
+// formatAmount formats an amount with thousand separators, e.g. "1.234.567".
+func formatAmount(amount models.Money) string {
+	digits := strconv.FormatInt(int64(amount), 10)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	var out strings.Builder
+	if negative {
+		out.WriteByte('-')
+	}
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte('.')
+		}
+		out.WriteRune(d)
+	}
+
+	return out.String()
 }
\ No newline at end of file
@@ -0,0 +1,192 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+type nativeRenderer struct {
+	httpClient *http.Client
+}
+
+// NewNativeRenderer draws invoices directly with a pure-Go PDF library, so
+// it works without wkhtmltopdf installed. Custom HTML templates are not
+// supported by this renderer.
+func NewNativeRenderer() Renderer {
+	return &nativeRenderer{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (r *nativeRenderer) Render(data InvoiceData) ([]byte, error) {
+	doc := gofpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(20, 20, 20)
+	doc.SetAutoPageBreak(true, 20)
+	doc.AddPage()
+
+	// Core fonts are cp1252; translate so names with accents survive
+	tr := doc.UnicodeTranslatorFromDescriptor("")
+	pageWidth, _ := doc.GetPageSize()
+	contentWidth := pageWidth - 40
+
+	// Header
+	if data.Restaurant.LogoURL != nil && *data.Restaurant.LogoURL != "" {
+		r.drawLogo(doc, *data.Restaurant.LogoURL, pageWidth)
+	}
+
+	doc.SetFont("Helvetica", "B", 20)
+	doc.CellFormat(contentWidth, 10, "INVOICE", "", 1, "C", false, 0, "")
+	doc.SetFont("Helvetica", "B", 14)
+	doc.CellFormat(contentWidth, 8, tr(data.Restaurant.Name), "", 1, "C", false, 0, "")
+
+	doc.SetFont("Helvetica", "", 10)
+	headerLines := []string{data.Restaurant.Address}
+	if data.Restaurant.Phone != "" {
+		headerLines = append(headerLines, "Tel: "+data.Restaurant.Phone)
+	}
+	if data.Restaurant.Email != "" {
+		headerLines = append(headerLines, data.Restaurant.Email)
+	}
+	if data.Restaurant.TaxID != "" {
+		headerLines = append(headerLines, "NPWP: "+data.Restaurant.TaxID)
+	}
+	for _, line := range headerLines {
+		if line != "" {
+			doc.CellFormat(contentWidth, 5, tr(line), "", 1, "C", false, 0, "")
+		}
+	}
+	doc.Ln(8)
+
+	// Invoice info
+	paymentMethod := "N/A"
+	if data.Payment != nil && data.Payment.PaymentType != nil && *data.Payment.PaymentType != "" {
+		paymentMethod = *data.Payment.PaymentType
+	}
+
+	info := [][2]string{
+		{"Invoice Number", data.InvoiceNumber},
+		{"Date", data.InvoiceDate},
+		{"Order Number", data.Order.OrderNumber},
+		{"Table", data.Order.Table.TableNumber},
+		{"Payment Method", paymentMethod},
+	}
+	for _, row := range info {
+		doc.SetFont("Helvetica", "B", 10)
+		doc.CellFormat(40, 6, row[0]+":", "", 0, "L", false, 0, "")
+		doc.SetFont("Helvetica", "", 10)
+		doc.CellFormat(contentWidth-40, 6, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	doc.Ln(6)
+
+	// Items table
+	colWidths := []float64{contentWidth - 85, 20, 32.5, 32.5}
+	doc.SetFont("Helvetica", "B", 10)
+	doc.SetFillColor(248, 249, 250)
+	for i, header := range []string{"Item", "Qty", "Unit Price", "Total"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		doc.CellFormat(colWidths[i], 9, header, "1", 0, align, true, 0, "")
+	}
+	doc.Ln(-1)
+
+	doc.SetFont("Helvetica", "", 10)
+	for _, item := range data.Items {
+		doc.CellFormat(colWidths[0], 8, tr(item.Name), "1", 0, "L", false, 0, "")
+		doc.CellFormat(colWidths[1], 8, fmt.Sprintf("%d", item.Quantity), "1", 0, "R", false, 0, "")
+		doc.CellFormat(colWidths[2], 8, formatRupiah(item.UnitPrice), "1", 0, "R", false, 0, "")
+		doc.CellFormat(colWidths[3], 8, formatRupiah(item.Total), "1", 1, "R", false, 0, "")
+	}
+	doc.Ln(6)
+
+	// Totals
+	totals := [][2]string{{"Subtotal", formatRupiah(data.Subtotal)}}
+	if data.Tax > 0 {
+		totals = append(totals, [2]string{data.TaxLabel, formatRupiah(data.Tax)})
+	}
+	if data.ServiceCharge > 0 {
+		totals = append(totals, [2]string{data.ServiceChargeLabel, formatRupiah(data.ServiceCharge)})
+	}
+	if data.Tip > 0 {
+		totals = append(totals, [2]string{"Tip", formatRupiah(data.Tip)})
+	}
+
+	labelX := pageWidth - 20 - 90
+	for _, row := range totals {
+		doc.SetX(labelX)
+		doc.CellFormat(50, 7, tr(row[0])+":", "", 0, "L", false, 0, "")
+		doc.CellFormat(40, 7, row[1], "", 1, "R", false, 0, "")
+	}
+	doc.SetX(labelX)
+	doc.SetFont("Helvetica", "B", 12)
+	doc.CellFormat(50, 9, "Total:", "T", 0, "L", false, 0, "")
+	doc.CellFormat(40, 9, formatRupiah(data.Total), "T", 1, "R", false, 0, "")
+	if data.IncludedTax > 0 {
+		doc.SetX(labelX)
+		doc.SetFont("Helvetica", "", 10)
+		doc.CellFormat(50, 7, tr(data.IncludedTaxLabel)+":", "", 0, "L", false, 0, "")
+		doc.CellFormat(40, 7, formatRupiah(data.IncludedTax), "", 1, "R", false, 0, "")
+	}
+
+	// Footer
+	if data.Restaurant.FooterText != "" {
+		doc.Ln(15)
+		doc.SetFont("Helvetica", "", 10)
+		doc.SetTextColor(102, 102, 102)
+		doc.MultiCell(contentWidth, 5, tr(data.Restaurant.FooterText), "", "C", false)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// drawLogo places the logo centered at the top. A logo that cannot be
+// fetched is skipped rather than failing the whole invoice.
+func (r *nativeRenderer) drawLogo(doc *gofpdf.Fpdf, logoURL string, pageWidth float64) {
+	resp, err := r.httpClient.Get(logoURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	imageType := ""
+	switch contentType := resp.Header.Get("Content-Type"); {
+	case strings.Contains(contentType, "png"):
+		imageType = "PNG"
+	case strings.Contains(contentType, "jpeg"), strings.Contains(contentType, "jpg"):
+		imageType = "JPG"
+	default:
+		return
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return
+	}
+
+	options := gofpdf.ImageOptions{ImageType: imageType, ReadDpi: true}
+	info := doc.RegisterImageOptionsReader("logo", options, bytes.NewReader(content))
+	if doc.Err() || info == nil {
+		doc.ClearError()
+		return
+	}
+
+	const height = 20.0
+	width := info.Width() * height / info.Height()
+	doc.ImageOptions("logo", (pageWidth-width)/2, doc.GetY(), width, height, true, options, 0, "")
+}
@@ -0,0 +1,134 @@
+package invoice
+
+import (
+	"fmt"
+	"lendral3n/ordering-system/internal/services/printer"
+	"strings"
+)
+
+// GenerateReceipt renders the receipt of a paid order as an ESC/POS byte
+// stream for a thermal printer. It carries the same invoice number as the
+// PDF invoice.
+func (s *Service) GenerateReceipt(orderID uint, paper printer.Paper) ([]byte, error) {
+	invoice, err := s.GetOrCreateInvoice(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.buildInvoiceData(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderReceipt(data, paper), nil
+}
+
+func renderReceipt(data *InvoiceData, paper printer.Paper) []byte {
+	doc := printer.NewDocument(paper)
+
+	// Header
+	doc.Align(printer.AlignCenter).Bold(true).DoubleSize(true)
+	doc.Line(data.Restaurant.Name)
+	doc.DoubleSize(false).Bold(false)
+	for _, line := range wrap(data.Restaurant.Address, doc.Columns()) {
+		doc.Line(line)
+	}
+	if data.Restaurant.Phone != "" {
+		doc.Line("Tel: " + data.Restaurant.Phone)
+	}
+	if data.Restaurant.TaxID != "" {
+		doc.Line("NPWP: " + data.Restaurant.TaxID)
+	}
+
+	// Order info
+	paidAt := data.Order.UpdatedAt
+	if data.Payment != nil && data.Payment.TransactionTime != nil {
+		paidAt = *data.Payment.TransactionTime
+	}
+
+	doc.Align(printer.AlignLeft).Divider()
+	doc.Columns2("No", data.InvoiceNumber)
+	doc.Columns2("Order", data.Order.OrderNumber)
+	doc.Columns2("Table", data.Order.Table.TableNumber)
+	doc.Columns2("Date", paidAt.Format("02/01/2006 15:04"))
+	doc.Divider()
+
+	// Items
+	for _, item := range data.Items {
+		doc.Line(item.Name)
+		doc.Columns2(fmt.Sprintf("  %d x %s", item.Quantity, formatAmount(item.UnitPrice)), formatAmount(item.Total))
+	}
+	doc.Divider()
+
+	// Totals
+	doc.Columns2("Subtotal", formatAmount(data.Subtotal))
+	if data.Tax > 0 {
+		doc.Columns2(data.TaxLabel, formatAmount(data.Tax))
+	}
+	if data.ServiceCharge > 0 {
+		doc.Columns2(data.ServiceChargeLabel, formatAmount(data.ServiceCharge))
+	}
+	if data.Tip > 0 {
+		doc.Columns2("Tip", formatAmount(data.Tip))
+	}
+	doc.Bold(true).Columns2("TOTAL", formatRupiah(data.Total)).Bold(false)
+	if data.IncludedTax > 0 {
+		doc.Columns2(data.IncludedTaxLabel, formatAmount(data.IncludedTax))
+	}
+
+	if data.Payment != nil && data.Payment.PaymentType != nil && *data.Payment.PaymentType != "" {
+		doc.Columns2("Paid by", *data.Payment.PaymentType)
+	}
+	if data.Payment != nil && data.Payment.TenderedAmount != nil && data.Payment.ChangeAmount != nil {
+		doc.Columns2("Cash", formatAmount(*data.Payment.TenderedAmount))
+		doc.Columns2("Change", formatAmount(*data.Payment.ChangeAmount))
+	}
+	if data.Payment != nil && data.Payment.ApprovalCode != nil {
+		doc.Columns2("Approval", *data.Payment.ApprovalCode)
+	}
+
+	// Footer
+	if data.Restaurant.FooterText != "" {
+		doc.Divider().Align(printer.AlignCenter)
+		for _, line := range wrap(data.Restaurant.FooterText, doc.Columns()) {
+			doc.Line(line)
+		}
+	}
+
+	doc.Feed(3).Cut()
+	return doc.Bytes()
+}
+
+// wrap breaks text into lines of at most width characters on word
+// boundaries, splitting words that are longer than a line.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
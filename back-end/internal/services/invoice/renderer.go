@@ -0,0 +1,67 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"lendral3n/ordering-system/internal/services/pdf"
+	"os"
+)
+
+// Renderer turns invoice data into a PDF document.
+type Renderer interface {
+	Render(data InvoiceData) ([]byte, error)
+}
+
+// Renderer kinds selectable through INVOICE_RENDERER
+const (
+	RendererNative      = "native"      // pure Go, no external binaries
+	RendererWkhtmltopdf = "wkhtmltopdf" // HTML template rendered by the wkhtmltopdf binary
+)
+
+// NewRenderer builds the renderer for the configured kind. The template path
+// only applies to the wkhtmltopdf renderer.
+func NewRenderer(kind, templatePath string) (Renderer, error) {
+	switch kind {
+	case RendererNative, "":
+		return NewNativeRenderer(), nil
+	case RendererWkhtmltopdf:
+		return NewHTMLRenderer(templatePath)
+	default:
+		return nil, fmt.Errorf("unknown invoice renderer %q", kind)
+	}
+}
+
+type htmlRenderer struct {
+	template *template.Template
+}
+
+// NewHTMLRenderer renders invoices from an HTML template through
+// wkhtmltopdf. When templatePath is set the template is loaded from that
+// file instead of the built-in one; it receives the same InvoiceData.
+func NewHTMLRenderer(templatePath string) (Renderer, error) {
+	tmplStr := defaultTemplate
+	if templatePath != "" {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read invoice template: %w", err)
+		}
+		tmplStr = string(content)
+	}
+
+	tmpl, err := template.New("invoice").Parse(tmplStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse invoice template: %w", err)
+	}
+
+	return &htmlRenderer{template: tmpl}, nil
+}
+
+func (r *htmlRenderer) Render(data InvoiceData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to generate HTML: %w", err)
+	}
+
+	return pdf.FromHTML(buf.String(), pdf.DefaultOptions())
+}
@@ -0,0 +1,111 @@
+package invoice
+
+// defaultTemplate is the built-in invoice layout. Custom templates loaded
+// from INVOICE_TEMPLATE_PATH are executed with the same InvoiceData.
+const defaultTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .header img { max-height: 80px; margin-bottom: 10px; }
+        .invoice-info { margin-bottom: 20px; }
+        .invoice-info div { margin: 5px 0; }
+        table { width: 100%; border-collapse: collapse; margin: 20px 0; }
+        th, td { border: 1px solid #ddd; padding: 12px; text-align: left; }
+        th { background-color: #f8f9fa; font-weight: bold; }
+        .text-right { text-align: right; }
+        .totals { margin-top: 20px; }
+        .totals table { width: 300px; margin-left: auto; }
+        .totals td { border: none; padding: 8px; }
+        .grand-total { font-size: 1.2em; font-weight: bold; }
+        .footer { margin-top: 40px; text-align: center; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        {{if .Restaurant.LogoURL}}<img src="{{.Restaurant.LogoURL}}" alt="{{.Restaurant.Name}}"><br>{{end}}
+        <h1>INVOICE</h1>
+        <h2>{{.Restaurant.Name}}</h2>
+        <p>
+            {{.Restaurant.Address}}
+            {{if .Restaurant.Phone}}<br>Tel: {{.Restaurant.Phone}}{{end}}
+            {{if .Restaurant.Email}}<br>{{.Restaurant.Email}}{{end}}
+            {{if .Restaurant.TaxID}}<br>NPWP: {{.Restaurant.TaxID}}{{end}}
+        </p>
+    </div>
+
+    <div class="invoice-info">
+        <div><strong>Invoice Number:</strong> {{.InvoiceNumber}}</div>
+        <div><strong>Date:</strong> {{.InvoiceDate}}</div>
+        <div><strong>Order Number:</strong> {{.Order.OrderNumber}}</div>
+        <div><strong>Table:</strong> {{.Order.Table.TableNumber}}</div>
+        <div><strong>Payment Method:</strong> {{if .Payment.PaymentType}}{{.Payment.PaymentType}}{{else}}N/A{{end}}</div>
+    </div>
+
+    <table>
+        <thead>
+            <tr>
+                <th>Item</th>
+                <th class="text-right">Qty</th>
+                <th class="text-right">Unit Price</th>
+                <th class="text-right">Total</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Items}}
+            <tr>
+                <td>{{.Name}}</td>
+                <td class="text-right">{{.Quantity}}</td>
+                <td class="text-right">Rp {{.UnitPrice}}</td>
+                <td class="text-right">Rp {{.Total}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+
+    <div class="totals">
+        <table>
+            <tr>
+                <td>Subtotal:</td>
+                <td class="text-right">Rp {{.Subtotal}}</td>
+            </tr>
+            {{if .Tax}}
+            <tr>
+                <td>{{.TaxLabel}}:</td>
+                <td class="text-right">Rp {{.Tax}}</td>
+            </tr>
+            {{end}}
+            {{if .ServiceCharge}}
+            <tr>
+                <td>{{.ServiceChargeLabel}}:</td>
+                <td class="text-right">Rp {{.ServiceCharge}}</td>
+            </tr>
+            {{end}}
+            {{if .Tip}}
+            <tr>
+                <td>Tip:</td>
+                <td class="text-right">Rp {{.Tip}}</td>
+            </tr>
+            {{end}}
+            <tr class="grand-total">
+                <td>Total:</td>
+                <td class="text-right">Rp {{.Total}}</td>
+            </tr>
+            {{if .IncludedTax}}
+            <tr>
+                <td>{{.IncludedTaxLabel}}:</td>
+                <td class="text-right">Rp {{.IncludedTax}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+
+    <div class="footer">
+        <p>{{.Restaurant.FooterText}}</p>
+    </div>
+</body>
+</html>
+`
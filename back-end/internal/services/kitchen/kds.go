@@ -0,0 +1,152 @@
+package kitchen
+
+import (
+	"errors"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/orderstatus"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCannotBump   = errors.New("item cannot be bumped from its current status")
+	ErrCannotRecall = errors.New("item cannot be recalled from its current status")
+)
+
+// stationExpr resolves an order item's station in SQL, mirroring
+// MenuItem.StationName.
+const stationExpr = "COALESCE(NULLIF(menu_items.station, ''), NULLIF(menu_categories.station, ''), 'kitchen')"
+
+// QueueItem is an order item as shown on a kitchen display.
+type QueueItem struct {
+	OrderItemID    uint      `json:"order_item_id"`
+	OrderID        uint      `json:"order_id"`
+	OrderNumber    string    `json:"order_number"`
+	TableNumber    string    `json:"table_number"`
+	Name           string    `json:"name"`
+	Quantity       int       `json:"quantity"`
+	Notes          *string   `json:"notes"`
+	Status         string    `json:"status"`
+	OrderedAt      time.Time `json:"ordered_at"`
+	ElapsedSeconds int       `json:"elapsed_seconds"`
+}
+
+// StationQueue returns the station's pending and preparing items, oldest
+// order first.
+func (s *Service) StationQueue(station string) ([]QueueItem, error) {
+	var items []models.OrderItem
+	err := s.db.
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Joins("JOIN menu_items ON menu_items.id = order_items.menu_item_id").
+		Joins("JOIN menu_categories ON menu_categories.id = menu_items.category_id").
+		Where("order_items.status IN ?", []string{models.OrderItemStatusPending, models.OrderItemStatusPreparing}).
+		Where("orders.status <> ?", models.OrderStatusCancelled).
+		Where(stationExpr+" = ?", station).
+		Preload("MenuItem").
+		Preload("Order.Table").
+		Order("orders.created_at, order_items.id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	queue := make([]QueueItem, 0, len(items))
+	for _, item := range items {
+		queue = append(queue, QueueItem{
+			OrderItemID:    item.ID,
+			OrderID:        item.OrderID,
+			OrderNumber:    item.Order.OrderNumber,
+			TableNumber:    item.Order.Table.TableNumber,
+			Name:           item.MenuItem.Name,
+			Quantity:       item.Quantity,
+			Notes:          item.Notes,
+			Status:         item.Status,
+			OrderedAt:      item.Order.CreatedAt,
+			ElapsedSeconds: int(now.Sub(item.Order.CreatedAt).Seconds()),
+		})
+	}
+
+	return queue, nil
+}
+
+// OrderStations lists the stations that prepare items of the order.
+func (s *Service) OrderStations(orderID uint) ([]string, error) {
+	order, err := s.getOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return sortedStations(groupByStation(order.OrderItems)), nil
+}
+
+// ItemStation returns the station that prepares an order item.
+func (s *Service) ItemStation(itemID uint) (string, error) {
+	var item models.OrderItem
+	if err := s.db.Preload("MenuItem.Category").First(&item, itemID).Error; err != nil {
+		return "", err
+	}
+	return item.MenuItem.StationName(), nil
+}
+
+// Bump moves an item one step forward: pending -> preparing -> ready. The
+// order is returned when its derived status changed, nil otherwise.
+func (s *Service) Bump(itemID uint, change orderstatus.Change) (*models.OrderItem, *models.Order, error) {
+	return s.moveItem(itemID, change, func(status string) (string, error) {
+		switch status {
+		case models.OrderItemStatusPending:
+			return models.OrderItemStatusPreparing, nil
+		case models.OrderItemStatusPreparing:
+			return models.OrderItemStatusReady, nil
+		}
+		return "", ErrCannotBump
+	})
+}
+
+// Recall undoes a bump, bringing the item back onto the display.
+func (s *Service) Recall(itemID uint, change orderstatus.Change) (*models.OrderItem, *models.Order, error) {
+	return s.moveItem(itemID, change, func(status string) (string, error) {
+		switch status {
+		case models.OrderItemStatusReady:
+			return models.OrderItemStatusPreparing, nil
+		case models.OrderItemStatusPreparing:
+			return models.OrderItemStatusPending, nil
+		}
+		return "", ErrCannotRecall
+	})
+}
+
+func (s *Service) moveItem(itemID uint, change orderstatus.Change, next func(status string) (string, error)) (*models.OrderItem, *models.Order, error) {
+	var item models.OrderItem
+	var changedOrder *models.Order
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so concurrent bumps from two screens apply in turn
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("MenuItem.Category").First(&item, itemID).Error; err != nil {
+			return err
+		}
+
+		status, err := next(item.Status)
+		if err != nil {
+			return err
+		}
+
+		if err := orderstatus.TransitionItem(tx, &item, status, change); err != nil {
+			return err
+		}
+
+		order, changed, err := orderstatus.Sync(tx, item.OrderID, change)
+		if err != nil {
+			return err
+		}
+		if changed {
+			changedOrder = order
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &item, changedOrder, nil
+}
@@ -0,0 +1,193 @@
+package kitchen
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/printer"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNoPrinter = errors.New("no printer configured for station")
+
+type Service struct {
+	db       *gorm.DB
+	printers map[string]printer.Sink
+	fallback printer.Sink
+	paper    printer.Paper
+}
+
+// NewService routes tickets to the printer of their station. Stations
+// without a printer go to fallback, typically a spool directory; fallback
+// may be nil.
+func NewService(db *gorm.DB, printers map[string]printer.Sink, fallback printer.Sink, paper printer.Paper) *Service {
+	return &Service{
+		db:       db,
+		printers: printers,
+		fallback: fallback,
+		paper:    paper,
+	}
+}
+
+// PrintOrderTickets creates one ticket per station for the order's items
+// and prints them. Stations that already have a ticket are skipped, so a
+// repeated call never double prints; use Reprint for that.
+func (s *Service) PrintOrderTickets(orderID uint) ([]models.KitchenTicket, error) {
+	order, err := s.getOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := groupByStation(order.OrderItems)
+	tickets := make([]models.KitchenTicket, 0, len(groups))
+	var printErr error
+
+	for _, station := range sortedStations(groups) {
+		ticket := models.KitchenTicket{
+			OrderID: order.ID,
+			Station: station,
+			Status:  models.TicketStatusPending,
+		}
+
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&ticket)
+		if result.Error != nil {
+			return tickets, fmt.Errorf("failed to create ticket: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := s.print(&ticket, order, groups[station], false); err != nil && printErr == nil {
+			printErr = err
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, printErr
+}
+
+// Reprint prints an existing ticket again, marked as a reprint.
+func (s *Service) Reprint(ticketID uint) (*models.KitchenTicket, error) {
+	var ticket models.KitchenTicket
+	if err := s.db.First(&ticket, ticketID).Error; err != nil {
+		return nil, err
+	}
+
+	order, err := s.getOrder(ticket.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := groupByStation(order.OrderItems)[ticket.Station]
+	err = s.print(&ticket, order, items, ticket.PrintCount > 0)
+	return &ticket, err
+}
+
+func (s *Service) GetOrderTickets(orderID uint) ([]models.KitchenTicket, error) {
+	var tickets []models.KitchenTicket
+	err := s.db.Where("order_id = ?", orderID).Order("station").Find(&tickets).Error
+	return tickets, err
+}
+
+func (s *Service) getOrder(orderID uint) (*models.Order, error) {
+	var order models.Order
+	err := s.db.Preload("OrderItems.MenuItem.Category").Preload("Table").First(&order, orderID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// print sends the ticket to its station and records the outcome.
+func (s *Service) print(ticket *models.KitchenTicket, order *models.Order, items []models.OrderItem, reprint bool) error {
+	sink := s.printers[ticket.Station]
+	if sink == nil {
+		sink = s.fallback
+	}
+
+	err := ErrNoPrinter
+	if sink != nil {
+		content := renderTicket(order, ticket.Station, items, reprint, s.paper)
+		err = sink.Print(fmt.Sprintf("%s-%s", order.OrderNumber, ticket.Station), content)
+	}
+
+	if err != nil {
+		message := err.Error()
+		ticket.Status = models.TicketStatusFailed
+		ticket.LastError = &message
+	} else {
+		now := time.Now()
+		ticket.Status = models.TicketStatusPrinted
+		ticket.PrintCount++
+		ticket.PrintedAt = &now
+		ticket.LastError = nil
+	}
+
+	if saveErr := s.db.Save(ticket).Error; saveErr != nil {
+		return fmt.Errorf("failed to save ticket: %w", saveErr)
+	}
+
+	return err
+}
+
+func renderTicket(order *models.Order, station string, items []models.OrderItem, reprint bool, paper printer.Paper) []byte {
+	doc := printer.NewDocument(paper)
+
+	// Header
+	doc.Align(printer.AlignCenter).Bold(true).DoubleSize(true)
+	doc.Line(strings.ToUpper(station))
+	if reprint {
+		doc.Line("** REPRINT **")
+	}
+	doc.Line("Table " + order.Table.TableNumber)
+	doc.DoubleSize(false).Bold(false)
+
+	doc.Align(printer.AlignLeft).Divider()
+	doc.Columns2("Order", order.OrderNumber)
+	doc.Columns2("Time", order.CreatedAt.Format("02/01 15:04"))
+	doc.Divider()
+
+	// Items
+	for _, item := range items {
+		doc.Bold(true).Line(fmt.Sprintf("%dx %s", item.Quantity, item.MenuItem.Name)).Bold(false)
+		if item.Notes != nil && *item.Notes != "" {
+			doc.Line("   * " + *item.Notes)
+		}
+	}
+
+	if order.Notes != nil && *order.Notes != "" {
+		doc.Divider()
+		doc.Line("Note: " + *order.Notes)
+	}
+
+	doc.Feed(3).Cut()
+	return doc.Bytes()
+}
+
+// groupByStation splits active order items by preparation station. Items
+// must have MenuItem.Category loaded.
+func groupByStation(items []models.OrderItem) map[string][]models.OrderItem {
+	groups := make(map[string][]models.OrderItem)
+	for _, item := range items {
+		if item.Status == models.OrderItemStatusCancelled {
+			continue
+		}
+		station := item.MenuItem.StationName()
+		groups[station] = append(groups[station], item)
+	}
+	return groups
+}
+
+func sortedStations(groups map[string][]models.OrderItem) []string {
+	stations := make([]string, 0, len(groups))
+	for station := range groups {
+		stations = append(stations, station)
+	}
+	sort.Strings(stations)
+	return stations
+}
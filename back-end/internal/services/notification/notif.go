@@ -24,11 +24,12 @@ type Client struct {
 	send    chan []byte
 	role    string
 	tableID int
+	station string
 }
 
 type Message struct {
 	Type    string      `json:"type"`
-	Target  string      `json:"target"` // "all", "staff", "table:{id}"
+	Target  string      `json:"target"` // "all", "staff", "table:{id}", "station:{name}"
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
 }
@@ -100,6 +101,10 @@ func (h *Hub) shouldSendToClient(client *Client, msg Message) bool {
 			fmt.Sscanf(msg.Target, "table:%d", &tableID)
 			return client.tableID == tableID
 		}
+		// Kitchen displays subscribe with ?station=grill
+		if len(msg.Target) > 8 && msg.Target[:8] == "station:" {
+			return client.station != "" && client.station == msg.Target[8:]
+		}
 	}
 	return false
 }
@@ -108,6 +113,7 @@ func (h *Hub) HandleWebSocket(c *websocket.Conn) {
 	// Get client info from query params
 	role := c.Query("role", "customer")
 	tableID := c.Query("table_id", "0")
+	station := c.Query("station")
 
 	tableIDInt := 0
 	fmt.Sscanf(tableID, "%d", &tableIDInt)
@@ -118,6 +124,7 @@ func (h *Hub) HandleWebSocket(c *websocket.Conn) {
 		send:    make(chan []byte, 256),
 		role:    role,
 		tableID: tableIDInt,
+		station: station,
 	}
 
 	client.hub.register <- client
@@ -239,6 +246,21 @@ func (h *Hub) BroadcastAssistanceRequest(tableID uint, tableNumber string) {
 	h.broadcast <- mustMarshal(msg)
 }
 
+// BroadcastStationQueue pushes the current queue of a kitchen station to
+// the displays subscribed to it.
+func (h *Hub) BroadcastStationQueue(station string, queue interface{}) {
+	msg := Message{
+		Type:    "kds_queue",
+		Target:  fmt.Sprintf("station:%s", station),
+		Message: fmt.Sprintf("Queue updated for %s", station),
+		Data: map[string]interface{}{
+			"station": station,
+			"items":   queue,
+		},
+	}
+	h.broadcast <- mustMarshal(msg)
+}
+
 func mustMarshal(v interface{}) []byte {
 	data, err := json.Marshal(v)
 	if err != nil {
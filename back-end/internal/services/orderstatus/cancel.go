@@ -0,0 +1,164 @@
+package orderstatus
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/pricing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InventoryReasonOrderCancelled marks stock returned by a cancelled order.
+const InventoryReasonOrderCancelled = "order_cancelled"
+
+// Cancel cancels an order together with its remaining items and returns
+// the stock they took. check, when set, runs on the locked order before
+// anything changes and can veto the cancellation.
+func Cancel(tx *gorm.DB, orderID uint, change Change, check func(order *models.Order) error) (*models.Order, error) {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("OrderItems").First(&order, orderID).Error; err != nil {
+		return nil, err
+	}
+
+	if check != nil {
+		if err := check(&order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := TransitionOrder(tx, &order, models.OrderStatusCancelled, change); err != nil {
+		return nil, err
+	}
+
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		if item.Status == models.OrderItemStatusCancelled {
+			continue
+		}
+		if err := TransitionItem(tx, item, models.OrderItemStatusCancelled, change); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := restoreStock(tx, order.OrderItems); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// CancelItem cancels one item of an order, returns its stock and prices the
+// order again without it, so the bill and the gateway line items keep adding
+// up. Cancelling the last remaining item cancels the whole order through
+// Cancel; the order is returned in that case, nil otherwise. Otherwise the
+// order status is left alone; call Sync afterwards.
+func CancelItem(tx *gorm.DB, item *models.OrderItem, change Change) (*models.Order, error) {
+	if err := TransitionItem(tx, item, models.OrderItemStatusCancelled, change); err != nil {
+		return nil, err
+	}
+	if err := restoreStock(tx, []models.OrderItem{*item}); err != nil {
+		return nil, err
+	}
+
+	var remaining int64
+	err := tx.Model(&models.OrderItem{}).
+		Where("order_id = ? AND status <> ?", item.OrderID, models.OrderItemStatusCancelled).
+		Count(&remaining).Error
+	if err != nil {
+		return nil, err
+	}
+	if remaining == 0 {
+		return Cancel(tx, item.OrderID, change, nil)
+	}
+
+	return nil, reprice(tx, item.OrderID)
+}
+
+// reprice saves the order's totals and charges after its items changed.
+func reprice(tx *gorm.DB, orderID uint) error {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("OrderItems").First(&order, orderID).Error; err != nil {
+		return err
+	}
+
+	var charges []models.OrderCharge
+	if err := tx.Where("order_id = ?", orderID).Order("id").Find(&charges).Error; err != nil {
+		return err
+	}
+
+	pricing.Reprice(&order, charges)
+
+	err := tx.Model(&order).Updates(map[string]interface{}{
+		"total_amount":   order.TotalAmount,
+		"tax_rate":       order.TaxRate,
+		"tax_amount":     order.TaxAmount,
+		"service_rate":   order.ServiceRate,
+		"service_charge": order.ServiceCharge,
+		"included_tax":   order.IncludedTax,
+		"grand_total":    order.GrandTotal,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range order.Charges {
+		if err := tx.Save(&order.Charges[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreStock returns whatever stock the items still hold. The amount is
+// taken from the inventory log, so untracked items and stock that was
+// already returned are left alone.
+func restoreStock(tx *gorm.DB, items []models.OrderItem) error {
+	itemIDs := make([]uint, 0, len(items))
+	menuItemIDs := make(map[uint]uint, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ID)
+		menuItemIDs[item.ID] = item.MenuItemID
+	}
+
+	var held []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := tx.Model(&models.InventoryLog{}).
+		Select("order_item_id, SUM(quantity_change) AS quantity").
+		Where("order_item_id IN ?", itemIDs).
+		Group("order_item_id").
+		Scan(&held).Error
+	if err != nil {
+		return err
+	}
+
+	for _, h := range held {
+		if h.Quantity >= 0 {
+			continue
+		}
+
+		restored := -h.Quantity
+		orderItemID := h.OrderItemID
+		menuItemID := menuItemIDs[orderItemID]
+
+		err := tx.Model(&models.MenuItem{}).
+			Where("id = ? AND stock_quantity IS NOT NULL", menuItemID).
+			Update("stock_quantity", gorm.Expr("stock_quantity + ?", restored)).Error
+		if err != nil {
+			return err
+		}
+
+		log := models.InventoryLog{
+			MenuItemID:     menuItemID,
+			QuantityChange: restored,
+			Reason:         InventoryReasonOrderCancelled,
+			OrderItemID:    &orderItemID,
+		}
+		if err := tx.Create(&log).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package orderstatus
+
+import (
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransitionError reports a status change the state machine does not allow.
+type TransitionError struct {
+	Entity string // "order" or "item"
+	From   string
+	To     string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change %s status from %s to %s", e.Entity, e.From, e.To)
+}
+
+// Change describes who made a transition and why. A nil StaffID means the
+// customer or the system.
+type Change struct {
+	StaffID *uint
+	Reason  string
+}
+
+// derivedReason is recorded when the order status follows its items.
+const derivedReason = "Derived from item statuses"
+
+// RecordCreated writes the first timeline event of a new order.
+func RecordCreated(tx *gorm.DB, order *models.Order, change Change) error {
+	return recordEvent(tx, order.ID, nil, "", order.Status, change)
+}
+
+// TransitionOrder moves an order to a new status if the state machine
+// allows it and records the event. Moving to the current status is a no-op.
+func TransitionOrder(tx *gorm.DB, order *models.Order, to string, change Change) error {
+	if order.Status == to {
+		return nil
+	}
+	if !models.CanTransitionOrder(order.Status, to) {
+		return &TransitionError{Entity: "order", From: order.Status, To: to}
+	}
+
+	return setOrderStatus(tx, order, to, change)
+}
+
+// TransitionOrderWithItems applies a status set by staff. Preparing, ready
+// and served follow the items, so the items still behind the new status are
+// moved up to it in the same transaction. A status the items would not roll
+// up to, such as preparing while every item is ready, is refused, so the
+// order and its items never disagree.
+func TransitionOrderWithItems(tx *gorm.DB, order *models.Order, to string, change Change) error {
+	var items []models.OrderItem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", order.ID).Find(&items).Error; err != nil {
+		return err
+	}
+	return transitionWithItems(tx, order, items, to, change)
+}
+
+// itemProgress ranks the item statuses an order status can be cascaded to.
+// Pending items rank 0.
+var itemProgress = map[string]int{
+	models.OrderItemStatusPreparing: 1,
+	models.OrderItemStatusReady:     2,
+	models.OrderItemStatusServed:    3,
+}
+
+func transitionWithItems(tx *gorm.DB, order *models.Order, items []models.OrderItem, to string, change Change) error {
+	if order.Status == to {
+		return nil
+	}
+	if !models.CanTransitionOrder(order.Status, to) {
+		return &TransitionError{Entity: "order", From: order.Status, To: to}
+	}
+
+	// Order statuses that match an item status are reached by the items
+	if target, ok := itemProgress[to]; ok {
+		for i := range items {
+			item := &items[i]
+			if item.Status == models.OrderItemStatusCancelled || itemProgress[item.Status] >= target {
+				continue
+			}
+			if err := TransitionItem(tx, item, to, change); err != nil {
+				return err
+			}
+		}
+	}
+
+	if models.DeriveOrderStatus(to, items) != to {
+		return &TransitionError{Entity: "order", From: order.Status, To: to}
+	}
+	return setOrderStatus(tx, order, to, change)
+}
+
+// setOrderStatus writes an order status and its event without checking the
+// state machine.
+func setOrderStatus(tx *gorm.DB, order *models.Order, to string, change Change) error {
+	if err := tx.Model(order).Update("status", to).Error; err != nil {
+		return err
+	}
+	if err := recordEvent(tx, order.ID, nil, order.Status, to, change); err != nil {
+		return err
+	}
+	order.Status = to
+
+	if to == models.OrderStatusReady {
+		notification := models.Notification{
+			OrderID: &order.ID,
+			Type:    models.NotificationOrderReady,
+			Message: fmt.Sprintf("Order #%s is ready to serve", order.OrderNumber),
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TransitionItem is the order item counterpart of TransitionOrder. It does
+// not touch the order; call Sync afterwards.
+func TransitionItem(tx *gorm.DB, item *models.OrderItem, to string, change Change) error {
+	if item.Status == to {
+		return nil
+	}
+	if !models.CanTransitionOrderItem(item.Status, to) {
+		return &TransitionError{Entity: "item", From: item.Status, To: to}
+	}
+
+	if err := tx.Model(item).Update("status", to).Error; err != nil {
+		return err
+	}
+	if err := recordEvent(tx, item.OrderID, &item.ID, item.Status, to, change); err != nil {
+		return err
+	}
+	item.Status = to
+
+	return nil
+}
+
+// Sync recomputes an order's status from its items. Call it inside the
+// transaction that changed the items so both commit together. It returns
+// the order and whether its status changed; callers broadcast after commit.
+func Sync(tx *gorm.DB, orderID uint, change Change) (*models.Order, bool, error) {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+		return nil, false, err
+	}
+
+	var items []models.OrderItem
+	if err := tx.Where("order_id = ?", orderID).Find(&items).Error; err != nil {
+		return nil, false, err
+	}
+
+	changed, err := syncOrder(tx, &order, items, change)
+	if err != nil {
+		return nil, false, err
+	}
+	return &order, changed, nil
+}
+
+// syncOrder applies the status derived from the items. The items are the
+// source of truth, so the roll-up is not limited to the manual transitions:
+// an order staff marked served follows its items back when the kitchen
+// recalls or bumps one. Cancelling is left to Cancel, which also returns
+// stock and is reached through CancelItem when the last item goes.
+func syncOrder(tx *gorm.DB, order *models.Order, items []models.OrderItem, change Change) (bool, error) {
+	status := models.DeriveOrderStatus(order.Status, items)
+	if status == order.Status || status == models.OrderStatusCancelled {
+		return false, nil
+	}
+
+	change.Reason = derivedReason
+	if err := setOrderStatus(tx, order, status, change); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Timeline returns the order's events, oldest first.
+func Timeline(db *gorm.DB, orderID uint) ([]models.OrderEvent, error) {
+	var events []models.OrderEvent
+	err := db.Preload("Staff").Where("order_id = ?", orderID).Order("created_at, id").Find(&events).Error
+	return events, err
+}
+
+func recordEvent(tx *gorm.DB, orderID uint, itemID *uint, from, to string, change Change) error {
+	event := models.OrderEvent{
+		OrderID:     orderID,
+		OrderItemID: itemID,
+		FromStatus:  from,
+		ToStatus:    to,
+		StaffID:     change.StaffID,
+	}
+	if change.Reason != "" {
+		event.Reason = &change.Reason
+	}
+
+	return tx.Create(&event).Error
+}
@@ -0,0 +1,145 @@
+package orderstatus
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds statements without a database, so status changes can be
+// checked without Postgres.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	return db
+}
+
+func TestSyncAfterManualServe(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		want  string
+	}{
+		{"item bumped to preparing", []string{models.OrderItemStatusPreparing, models.OrderItemStatusPending}, models.OrderStatusPreparing},
+		{"item bumped to ready", []string{models.OrderItemStatusReady, models.OrderItemStatusReady}, models.OrderStatusReady},
+		{"item recalled to pending", []string{models.OrderItemStatusPending, models.OrderItemStatusCancelled}, models.OrderStatusConfirmed},
+		{"all items served", []string{models.OrderItemStatusServed, models.OrderItemStatusServed}, models.OrderStatusServed},
+	}
+
+	db := dryRunDB(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Staff marked the order served without touching its items
+			order := models.Order{ID: 1, OrderNumber: "ORD-1", Status: models.OrderStatusServed}
+			items := make([]models.OrderItem, len(tt.items))
+			for i, status := range tt.items {
+				items[i] = models.OrderItem{ID: uint(i + 1), OrderID: order.ID, Status: status}
+			}
+
+			changed, err := syncOrder(db, &order, items, Change{})
+			if err != nil {
+				t.Fatalf("syncOrder: %v", err)
+			}
+			if order.Status != tt.want {
+				t.Errorf("status = %s, want %s", order.Status, tt.want)
+			}
+			if changed != (tt.want != models.OrderStatusServed) {
+				t.Errorf("changed = %v", changed)
+			}
+		})
+	}
+}
+
+func TestTransitionOrderKeepsManualRules(t *testing.T) {
+	db := dryRunDB(t)
+	order := models.Order{ID: 1, Status: models.OrderStatusServed}
+
+	err := TransitionOrder(db, &order, models.OrderStatusPreparing, Change{})
+	if _, ok := err.(*TransitionError); !ok {
+		t.Fatalf("err = %v, want a TransitionError", err)
+	}
+	if order.Status != models.OrderStatusServed {
+		t.Errorf("status = %s, want served", order.Status)
+	}
+}
+
+func TestSyncLeavesCancellingToCancel(t *testing.T) {
+	db := dryRunDB(t)
+	order := models.Order{ID: 1, Status: models.OrderStatusPreparing}
+	items := []models.OrderItem{
+		{ID: 1, OrderID: 1, Status: models.OrderItemStatusCancelled},
+		{ID: 2, OrderID: 1, Status: models.OrderItemStatusCancelled},
+	}
+
+	changed, err := syncOrder(db, &order, items, Change{})
+	if err != nil {
+		t.Fatalf("syncOrder: %v", err)
+	}
+	if changed || order.Status != models.OrderStatusPreparing {
+		t.Errorf("roll-up moved the order to %s, want it left to Cancel", order.Status)
+	}
+}
+
+func TestTransitionWithItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		items   []string
+		want    []string
+		refused bool
+	}{
+		{"served moves every item up", models.OrderStatusReady, models.OrderStatusServed,
+			[]string{models.OrderItemStatusReady, models.OrderItemStatusPreparing, models.OrderItemStatusCancelled},
+			[]string{models.OrderItemStatusServed, models.OrderItemStatusServed, models.OrderItemStatusCancelled}, false},
+		{"ready keeps served items", models.OrderStatusPreparing, models.OrderStatusReady,
+			[]string{models.OrderItemStatusServed, models.OrderItemStatusPending},
+			[]string{models.OrderItemStatusServed, models.OrderItemStatusReady}, false},
+		{"preparing starts pending items", models.OrderStatusConfirmed, models.OrderStatusPreparing,
+			[]string{models.OrderItemStatusPending, models.OrderItemStatusReady},
+			[]string{models.OrderItemStatusPreparing, models.OrderItemStatusReady}, false},
+		{"confirmed with nothing started", models.OrderStatusPending, models.OrderStatusConfirmed,
+			[]string{models.OrderItemStatusPending},
+			[]string{models.OrderItemStatusPending}, false},
+		{"preparing while every item is ready", models.OrderStatusConfirmed, models.OrderStatusPreparing,
+			[]string{models.OrderItemStatusReady, models.OrderItemStatusReady},
+			[]string{models.OrderItemStatusReady, models.OrderItemStatusReady}, true},
+		{"confirmed after the kitchen started", models.OrderStatusPreparing, models.OrderStatusConfirmed,
+			[]string{models.OrderItemStatusPreparing},
+			[]string{models.OrderItemStatusPreparing}, true},
+	}
+
+	db := dryRunDB(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := models.Order{ID: 1, OrderNumber: "ORD-1", Status: tt.from}
+			items := make([]models.OrderItem, len(tt.items))
+			for i, status := range tt.items {
+				items[i] = models.OrderItem{ID: uint(i + 1), OrderID: order.ID, Status: status}
+			}
+
+			err := transitionWithItems(db, &order, items, tt.to, Change{})
+			if _, ok := err.(*TransitionError); ok != tt.refused {
+				t.Fatalf("err = %v, refused = %v", err, tt.refused)
+			}
+			if !tt.refused && order.Status != tt.to {
+				t.Errorf("status = %s, want %s", order.Status, tt.to)
+			}
+			for i, item := range items {
+				if !tt.refused && item.Status != tt.want[i] {
+					t.Errorf("item %d = %s, want %s", i+1, item.Status, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordEvent stores a webhook notification with its raw payload. It reports
+// whether the same transaction status was already processed, in which case
+// the notification is a retry and must not be applied again.
+func RecordEvent(db *gorm.DB, provider string, status *TransactionStatus) (*models.PaymentEvent, bool, error) {
+	event := models.PaymentEvent{
+		Provider:       provider,
+		Reference:      status.OrderID,
+		TransactionID:  status.TransactionID,
+		ProviderStatus: status.ProviderStatus,
+		FraudStatus:    status.FraudStatus,
+		Status:         status.Status,
+		RawPayload:     status.Raw,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &event, false, nil
+	}
+
+	// Seen before: only a duplicate if the earlier delivery was applied
+	err := db.Where("transaction_id = ? AND provider_status = ? AND fraud_status = ?",
+		status.TransactionID, status.ProviderStatus, status.FraudStatus).
+		First(&event).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &event, event.ProcessedAt != nil, nil
+}
+
+// MarkEventProcessed records that a notification has been applied.
+func MarkEventProcessed(db *gorm.DB, event *models.PaymentEvent) error {
+	now := time.Now()
+	event.ProcessedAt = &now
+	return db.Model(event).Update("processed_at", now).Error
+}
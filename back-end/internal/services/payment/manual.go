@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProviderManual marks payments taken at the counter rather than through a
+// gateway. It is not selectable through PAYMENT_PROVIDER.
+const ProviderManual = "manual"
+
+var (
+	ErrUnknownPaymentMethod = errors.New("payment method must be cash or edc")
+	ErrInsufficientTender   = errors.New("tendered amount is less than the amount due")
+	ErrApprovalCodeRequired = errors.New("EDC payments require an approval code")
+	ErrGatewayPending       = errors.New("a gateway payment for the order is still open")
+)
+
+// ManualPayment is a payment taken by a cashier.
+type ManualPayment struct {
+	Method         string       // models.PaymentMethodCash or models.PaymentMethodEDC
+	Amount         models.Money // amount due
+	TenderedAmount models.Money // cash handed over, cash only
+	ApprovalCode   string       // terminal approval code, EDC only
+	SplitShareID   *uint        // set when paying one share of a split bill
+	StaffID        *uint
+}
+
+// RecordManual validates a cash or EDC payment and stores it as a settled
+// payment for the order. It does not touch the order itself. An order with
+// an open gateway checkout, on its own or on a session bill, cannot be paid
+// at the counter until that checkout fails or expires, or it would be paid
+// twice.
+func RecordManual(tx *gorm.DB, order *models.Order, req ManualPayment) (*models.Payment, error) {
+	amount := req.Amount
+
+	if req.SplitShareID == nil {
+		var open int64
+		err := tx.Model(&models.Payment{}).
+			Where("order_id = ? AND method = ? AND status = ? AND split_share_id IS NULL",
+				order.ID, models.PaymentMethodGateway, models.PaymentStatusPending).
+			Count(&open).Error
+		if err != nil {
+			return nil, err
+		}
+		if open > 0 {
+			return nil, ErrGatewayPending
+		}
+	}
+
+	record := models.Payment{
+		OrderID:         order.ID,
+		SplitShareID:    req.SplitShareID,
+		Provider:        ProviderManual,
+		Method:          req.Method,
+		Status:          models.PaymentStatusPaid,
+		MidtransOrderID: fmt.Sprintf("%s-%s-%d", order.OrderNumber, strings.ToUpper(req.Method), time.Now().UnixNano()),
+		PaymentType:     &req.Method,
+		GrossAmount:     amount,
+		Currency:        "IDR",
+		StaffID:         req.StaffID,
+	}
+
+	switch req.Method {
+	case models.PaymentMethodCash:
+		tendered := req.TenderedAmount
+		if tendered < amount {
+			return nil, ErrInsufficientTender
+		}
+		change := tendered - amount
+		record.TenderedAmount = &tendered
+		record.ChangeAmount = &change
+
+	case models.PaymentMethodEDC:
+		code := strings.TrimSpace(req.ApprovalCode)
+		if code == "" {
+			return nil, ErrApprovalCodeRequired
+		}
+		record.ApprovalCode = &code
+
+	default:
+		return nil, ErrUnknownPaymentMethod
+	}
+
+	now := time.Now()
+	record.TransactionTime = &now
+
+	if err := tx.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
@@ -6,8 +6,11 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"lendral3n/ordering-system/internal/config"
 	"lendral3n/ordering-system/internal/models"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +39,14 @@ func NewMidtransService(cfg *config.Config) *MidtransService {
 	snapClient.New(cfg.MidtransServerKey, env)
 	coreClient.New(cfg.MidtransServerKey, env)
 
+	if cfg.MidtransAPIURL != "" {
+		coreClient.HttpClient = &baseURLClient{
+			HttpClient: coreClient.HttpClient,
+			from:       env.BaseUrl(),
+			to:         strings.TrimRight(cfg.MidtransAPIURL, "/"),
+		}
+	}
+
 	return &MidtransService{
 		snapClient: snapClient,
 		coreClient: coreClient,
@@ -43,8 +54,14 @@ func NewMidtransService(cfg *config.Config) *MidtransService {
 	}
 }
 
+func (s *MidtransService) Name() string {
+	return ProviderMidtrans
+}
+
 type CreateTransactionRequest struct {
-	Order         *models.Order
+	OrderID       string       // provider order reference, unique per transaction
+	Amount        models.Money // gross amount, equal to the sum of Items
+	Items         []LineItem
 	CustomerName  string
 	CustomerPhone string
 	CustomerEmail string
@@ -56,61 +73,26 @@ type TransactionResponse struct {
 }
 
 func (s *MidtransService) CreateTransaction(req CreateTransactionRequest) (*TransactionResponse, error) {
-	// Prepare item details
-	items := make([]midtrans.ItemDetails, 0, len(req.Order.OrderItems)+2)
-
-	for _, item := range req.Order.OrderItems {
-		items = append(items, midtrans.ItemDetails{
-			ID:    fmt.Sprintf("ITEM-%d", item.MenuItemID),
-			Name:  item.MenuItem.Name,
-			Price: int64(item.UnitPrice),
-			Qty:   int32(item.Quantity),
-		})
-	}
-
-	// Add tax
-	if req.Order.TaxAmount > 0 {
-		items = append(items, midtrans.ItemDetails{
-			ID:    "TAX",
-			Name:  "Tax 10%",
-			Price: int64(req.Order.TaxAmount),
-			Qty:   1,
-		})
-	}
-
-	// Add service charge
-	if req.Order.ServiceCharge > 0 {
-		items = append(items, midtrans.ItemDetails{
-			ID:    "SERVICE",
-			Name:  "Service Charge 5%",
-			Price: int64(req.Order.ServiceCharge),
-			Qty:   1,
-		})
+	if LineItemsTotal(req.Items) != req.Amount {
+		return nil, ErrItemsMismatch
 	}
 
-	// Create customer details
-	custDetail := &midtrans.CustomerDetails{
-		FName: req.CustomerName,
-		Phone: req.CustomerPhone,
-	}
-
-	if req.CustomerEmail != "" {
-		custDetail.Email = req.CustomerEmail
-	}
+	items := midtransItems(req.Items)
+	custDetail := midtransCustomer(req)
 
 	// Create transaction request
 	// Fix: Use correct field name 'Items' instead of 'ItemDetails'
 	snapReq := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  req.Order.OrderNumber,
-			GrossAmt: int64(req.Order.GrandTotal),
+			OrderID:  req.OrderID,
+			GrossAmt: int64(req.Amount),
 		},
 		Items:          &items,
 		CustomerDetail: custDetail,
 		EnabledPayments: s.getEnabledPayments(),
 		Expiry: &snap.ExpiryDetails{
-			Duration: 1,
-			Unit:     "hour",
+			Duration: int64(TransactionExpiry / time.Minute),
+			Unit:     "minute",
 		},
 	}
 
@@ -126,12 +108,95 @@ func (s *MidtransService) CreateTransaction(req CreateTransactionRequest) (*Tran
 	}, nil
 }
 
-func (s *MidtransService) GetTransactionStatus(orderID string) (*coreapi.TransactionStatusResponse, error) {
+// CreateQRISCharge charges the transaction through the Core API as QRIS. The
+// returned code embeds the amount and settles through the webhook.
+func (s *MidtransService) CreateQRISCharge(req CreateTransactionRequest) (*QRISCharge, error) {
+	if LineItemsTotal(req.Items) != req.Amount {
+		return nil, ErrItemsMismatch
+	}
+
+	items := midtransItems(req.Items)
+	chargeReq := &coreapi.ChargeReq{
+		PaymentType: coreapi.PaymentTypeQris,
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderID,
+			GrossAmt: int64(req.Amount),
+		},
+		Items:           &items,
+		CustomerDetails: midtransCustomer(req),
+		CustomExpiry: &coreapi.CustomExpiry{
+			ExpiryDuration: int(TransactionExpiry / time.Minute),
+			Unit:           "minute",
+		},
+	}
+
+	resp, err := s.coreClient.ChargeTransaction(chargeReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create QRIS charge: %w", err)
+	}
+	if resp.QRString == "" {
+		return nil, fmt.Errorf("QRIS charge for %s returned no QR string", req.OrderID)
+	}
+
+	return &QRISCharge{
+		TransactionID: resp.TransactionID,
+		QRString:      resp.QRString,
+		ExpiresAt:     time.Now().Add(TransactionExpiry),
+	}, nil
+}
+
+func (s *MidtransService) GetTransactionStatus(orderID string) (*TransactionStatus, error) {
 	resp, err := s.coreClient.CheckTransaction(orderID)
 	if err != nil {
+		if err.GetStatusCode() == http.StatusNotFound {
+			return nil, ErrTransactionNotFound
+		}
 		return nil, fmt.Errorf("failed to check transaction status: %w", err)
 	}
-	return resp, nil
+
+	notif := NotificationPayload{
+		TransactionTime:   resp.TransactionTime,
+		TransactionStatus: resp.TransactionStatus,
+		TransactionID:     resp.TransactionID,
+		StatusMessage:     resp.StatusMessage,
+		PaymentType:       resp.PaymentType,
+		OrderID:           resp.OrderID,
+		GrossAmount:       resp.GrossAmount,
+		FraudStatus:       resp.FraudStatus,
+		PermataVANumber:   resp.PermataVaNumber,
+		Bank:              resp.Bank,
+	}
+	for _, va := range resp.VaNumbers {
+		notif.VANumbers = append(notif.VANumbers, VANumber{Bank: va.Bank, VANumber: va.VANumber})
+	}
+
+	status := notif.Status()
+	status.Raw, _ = json.Marshal(resp)
+	return status, nil
+}
+
+type RefundRequest struct {
+	RefundKey string // unique per refund; Midtrans rejects a reused key
+	Amount    models.Money
+	Reason    string
+}
+
+// RefundTransaction refunds all or part of a settled transaction through the Core API.
+func (s *MidtransService) RefundTransaction(orderID string, req RefundRequest) (*RefundResult, error) {
+	resp, err := s.coreClient.RefundTransaction(orderID, &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    int64(req.Amount),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund transaction: %w", err)
+	}
+
+	result := &RefundResult{Amount: resp.RefundAmount}
+	if resp.RefundChargebackID != 0 {
+		result.ProviderRefundID = strconv.Itoa(resp.RefundChargebackID)
+	}
+	return result, nil
 }
 
 func (s *MidtransService) VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool {
@@ -144,7 +209,7 @@ func (s *MidtransService) VerifySignature(orderID, statusCode, grossAmount, sign
 	return expectedSignature == signatureKey
 }
 
-func (s *MidtransService) ParseNotification(payload []byte) (*NotificationPayload, error) {
+func (s *MidtransService) ParseNotification(payload []byte) (*TransactionStatus, error) {
 	var notif NotificationPayload
 	if err := json.Unmarshal(payload, &notif); err != nil {
 		return nil, err
@@ -155,7 +220,60 @@ func (s *MidtransService) ParseNotification(payload []byte) (*NotificationPayloa
 		return nil, fmt.Errorf("invalid signature")
 	}
 
-	return &notif, nil
+	status := notif.Status()
+	status.Raw = payload
+	return status, nil
+}
+
+func (s *MidtransService) NormalizeStatus(providerStatus string) string {
+	return midtransStatus(providerStatus, "")
+}
+
+// midtransStatus maps a Midtrans transaction status to a payment status.
+// Card captures flagged for fraud review stay pending until Midtrans
+// settles or denies them.
+func midtransStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "capture":
+		if fraudStatus == "challenge" {
+			return models.PaymentStatusPending
+		}
+		return models.PaymentStatusPaid
+	case "settlement":
+		return models.PaymentStatusPaid
+	case "deny", "cancel", "expire", "failure":
+		return models.PaymentStatusFailed
+	case "refund", "chargeback":
+		return models.PaymentStatusRefunded
+	case "partial_refund", "partial_chargeback":
+		return models.PaymentStatusPartiallyRefunded
+	default:
+		return models.PaymentStatusPending
+	}
+}
+
+func midtransItems(lines []LineItem) []midtrans.ItemDetails {
+	items := make([]midtrans.ItemDetails, 0, len(lines))
+	for _, item := range lines {
+		items = append(items, midtrans.ItemDetails{
+			ID:    item.ID,
+			Name:  item.Name,
+			Price: int64(item.Price),
+			Qty:   int32(item.Quantity),
+		})
+	}
+	return items
+}
+
+func midtransCustomer(req CreateTransactionRequest) *midtrans.CustomerDetails {
+	custDetail := &midtrans.CustomerDetails{
+		FName: req.CustomerName,
+		Phone: req.CustomerPhone,
+	}
+	if req.CustomerEmail != "" {
+		custDetail.Email = req.CustomerEmail
+	}
+	return custDetail
 }
 
 func (s *MidtransService) getEnabledPayments() []snap.SnapPaymentType {
@@ -164,7 +282,7 @@ func (s *MidtransService) getEnabledPayments() []snap.SnapPaymentType {
 		snap.PaymentTypeBankTransfer,
 		snap.PaymentTypeCreditCard,
 		snap.PaymentTypeGopay,
-		// snap.PaymentTypeQris is not available in the current version
+		// QRIS is charged directly through CreateQRISCharge
 		snap.PaymentTypeShopeepay,
 		snap.PaymentTypeBCAKlikpay,
 		snap.PaymentTypeAlfamart,
@@ -233,6 +351,26 @@ func (p *NotificationPayload) GetBank() string {
 	return ""
 }
 
+// Status converts the notification into the provider neutral form.
+func (p *NotificationPayload) Status() *TransactionStatus {
+	status := &TransactionStatus{
+		OrderID:        p.OrderID,
+		TransactionID:  p.TransactionID,
+		Status:         midtransStatus(p.TransactionStatus, p.FraudStatus),
+		ProviderStatus: p.TransactionStatus,
+		PaymentType:    p.PaymentType,
+		GrossAmount:    p.GrossAmount,
+		VANumber:       p.GetVANumber(),
+		Bank:           p.GetBank(),
+		FraudStatus:    p.FraudStatus,
+		StatusMessage:  p.StatusMessage,
+	}
+	if transTime, err := p.GetTransactionTime(); err == nil {
+		status.TransactionTime = transTime
+	}
+	return status
+}
+
 func (p *NotificationPayload) GetTransactionTime() (*time.Time, error) {
 	if p.TransactionTime == "" {
 		return nil, nil
@@ -245,4 +383,16 @@ func (p *NotificationPayload) GetTransactionTime() (*time.Time, error) {
 	}
 
 	return &t, nil
+}
+
+// baseURLClient sends Core API calls to another host, such as a local stub
+// server, while keeping the request paths of the official client.
+type baseURLClient struct {
+	midtrans.HttpClient
+	from string
+	to   string
+}
+
+func (c *baseURLClient) Call(method string, url string, apiKey *string, options *midtrans.ConfigOptions, body io.Reader, result interface{}) *midtrans.Error {
+	return c.HttpClient.Call(method, strings.Replace(url, c.from, c.to, 1), apiKey, options, body, result)
 }
\ No newline at end of file
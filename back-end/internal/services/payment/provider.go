@@ -0,0 +1,185 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/config"
+	"lendral3n/ordering-system/internal/models"
+	"strconv"
+	"time"
+)
+
+// PaymentProvider is a payment gateway. Implementations translate their own
+// transaction states into the order payment statuses in models
+// (PaymentStatusPending, PaymentStatusPaid, ...) so handlers never deal with
+// gateway specific strings.
+type PaymentProvider interface {
+	// Name identifies the provider on stored payments, e.g. "midtrans".
+	Name() string
+	CreateTransaction(req CreateTransactionRequest) (*TransactionResponse, error)
+	// CreateQRISCharge creates the transaction as a dynamic QRIS code for
+	// the customer to scan. It settles through the webhook.
+	CreateQRISCharge(req CreateTransactionRequest) (*QRISCharge, error)
+	GetTransactionStatus(orderID string) (*TransactionStatus, error)
+	RefundTransaction(orderID string, req RefundRequest) (*RefundResult, error)
+	// ParseNotification authenticates and decodes a webhook payload.
+	ParseNotification(payload []byte) (*TransactionStatus, error)
+	// NormalizeStatus maps a raw provider status, such as one passed back on
+	// a redirect, to a payment status.
+	NormalizeStatus(providerStatus string) string
+}
+
+// Providers selectable through PAYMENT_PROVIDER
+const (
+	ProviderMidtrans = "midtrans"
+)
+
+// PaymentTypeQRIS asks for a QRIS code instead of the provider's payment page.
+const PaymentTypeQRIS = "qris"
+
+// TransactionExpiry is how long a customer has to complete a gateway payment
+// after it is created.
+const TransactionExpiry = time.Hour
+
+// ErrTransactionNotFound is returned by GetTransactionStatus when the provider
+// has no transaction for the reference, e.g. the customer never picked a
+// payment method.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrItemsMismatch is returned when a transaction's line items do not add up
+// to its amount.
+var ErrItemsMismatch = errors.New("line items do not add up to the transaction amount")
+
+// NewProvider builds the configured payment provider.
+func NewProvider(cfg *config.Config) (PaymentProvider, error) {
+	switch cfg.PaymentProvider {
+	case ProviderMidtrans, "":
+		return NewMidtransService(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+	}
+}
+
+// TransactionStatus is a provider's view of a transaction. Status holds the
+// normalized payment status; ProviderStatus keeps the raw value for display
+// and auditing.
+type TransactionStatus struct {
+	OrderID         string // provider order reference, Payment.MidtransOrderID
+	TransactionID   string
+	Status          string
+	ProviderStatus  string
+	PaymentType     string
+	GrossAmount     string
+	VANumber        string
+	Bank            string
+	FraudStatus     string
+	StatusMessage   string
+	TransactionTime *time.Time
+	Raw             []byte // payload as received from the provider
+}
+
+// statusRank orders payment statuses by how far along a payment is. A
+// payment never moves back to a lower rank.
+var statusRank = map[string]int{
+	models.PaymentStatusPending:           0,
+	models.PaymentStatusFailed:            1,
+	models.PaymentStatusPaid:              2,
+	models.PaymentStatusPartiallyRefunded: 3,
+	models.PaymentStatusRefunded:          4,
+}
+
+// StatusAdvances reports whether a payment in the current status may move to
+// next. Late or replayed provider updates, such as a pending notification
+// arriving after settlement, do not advance the payment and must be ignored.
+func StatusAdvances(current, next string) bool {
+	return statusRank[next] > statusRank[current]
+}
+
+// QRISCharge is a transaction awaiting payment by QRIS. QRString is the
+// EMVCo payload to render as a QR code.
+type QRISCharge struct {
+	TransactionID string
+	QRString      string
+	ExpiresAt     time.Time
+}
+
+// RefundResult is the outcome of an accepted refund.
+type RefundResult struct {
+	ProviderRefundID string
+	Amount           string
+}
+
+// LineItem is one line of a transaction as shown on the payment page.
+type LineItem struct {
+	ID       string
+	Name     string
+	Price    models.Money
+	Quantity int
+}
+
+// Line item IDs of the tax, service charge and tip lines
+const (
+	LineItemTax     = "TAX"
+	LineItemService = "SERVICE"
+	LineItemTip     = "TIP"
+)
+
+// OrderLineItems lists an order's items followed by its tax and service
+// charge, which together add up to the order's grand total. Cancelled items
+// are left out, as they are when the order is priced.
+func OrderLineItems(order *models.Order) []LineItem {
+	items := make([]LineItem, 0, len(order.OrderItems)+2)
+
+	for _, item := range order.OrderItems {
+		if item.Status == models.OrderItemStatusCancelled {
+			continue
+		}
+		items = append(items, LineItem{
+			ID:       fmt.Sprintf("ITEM-%d", item.MenuItemID),
+			Name:     item.MenuItem.Name,
+			Price:    item.UnitPrice,
+			Quantity: item.Quantity,
+		})
+	}
+
+	// Add tax
+	if order.TaxAmount > 0 {
+		items = append(items, LineItem{
+			ID:       LineItemTax,
+			Name:     fmt.Sprintf("Tax %s%%", strconv.FormatFloat(order.EffectiveTaxRate(), 'f', -1, 64)),
+			Price:    order.TaxAmount,
+			Quantity: 1,
+		})
+	}
+
+	// Add service charge
+	if order.ServiceCharge > 0 {
+		items = append(items, LineItem{
+			ID:       LineItemService,
+			Name:     fmt.Sprintf("Service Charge %s%%", strconv.FormatFloat(order.EffectiveServiceRate(), 'f', -1, 64)),
+			Price:    order.ServiceCharge,
+			Quantity: 1,
+		})
+	}
+
+	return items
+}
+
+// WithTip appends the tip as its own line so the items still add up to the
+// charged amount.
+func WithTip(items []LineItem, tip models.Money) []LineItem {
+	if tip <= 0 {
+		return items
+	}
+	return append(items, LineItem{ID: LineItemTip, Name: "Tip", Price: tip, Quantity: 1})
+}
+
+// LineItemsTotal adds up the lines. Gateways reject a transaction whose
+// lines do not add up to its gross amount.
+func LineItemsTotal(items []LineItem) models.Money {
+	var total models.Money
+	for _, item := range items {
+		total += item.Price.Times(item.Quantity)
+	}
+	return total
+}
@@ -0,0 +1,88 @@
+package payment
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/pricing"
+	"testing"
+)
+
+// pricedOrder prices an order the way checkout does.
+func pricedOrder(orderType string, items []models.OrderItem, rules ...models.TaxRule) models.Order {
+	order := models.Order{OrderType: orderType, OrderItems: items}
+	pricing.Apply(&order, rules)
+	return order
+}
+
+func TestOrderLineItemsAddUpToGrandTotal(t *testing.T) {
+	pb1 := models.TaxRule{Name: "PB1", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePB1, Rate: 10}
+	ppn := models.TaxRule{Name: "PPN", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePPN, Rate: 11, Inclusive: true}
+	service := models.TaxRule{Name: "Service", Kind: models.TaxRuleKindService, Rate: 5.5, DineInOnly: true}
+
+	items := []models.OrderItem{
+		{MenuItemID: 1, Quantity: 3, UnitPrice: 18333, Subtotal: 54999},
+		{MenuItemID: 2, Quantity: 1, UnitPrice: 7777, Subtotal: 7777, TaxExempt: true},
+	}
+	withCancelled := append([]models.OrderItem{
+		{MenuItemID: 3, Quantity: 2, UnitPrice: 45000, Subtotal: 90000, Status: models.OrderItemStatusCancelled},
+	}, items...)
+
+	tests := []struct {
+		name  string
+		order models.Order
+		tip   models.Money
+		lines int
+	}{
+		{"no charges", pricedOrder(models.OrderTypeDineIn, items), 0, 2},
+		{"exclusive tax and service", pricedOrder(models.OrderTypeDineIn, items, pb1, service), 0, 4},
+		{"inclusive tax has no line", pricedOrder(models.OrderTypeDineIn, items, ppn, service), 0, 3},
+		{"takeaway", pricedOrder(models.OrderTypeTakeaway, items, pb1, service), 0, 3},
+		{"cancelled items are left out", pricedOrder(models.OrderTypeDineIn, withCancelled, pb1, service), 0, 4},
+		{"tip", pricedOrder(models.OrderTypeDineIn, items, pb1, service), 5000, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := WithTip(OrderLineItems(&tt.order), tt.tip)
+			if len(lines) != tt.lines {
+				t.Errorf("got %d lines, want %d: %+v", len(lines), tt.lines, lines)
+			}
+			if total := LineItemsTotal(lines); total != tt.order.GrandTotal+tt.tip {
+				t.Errorf("lines add up to %d, want %d", total, tt.order.GrandTotal+tt.tip)
+			}
+		})
+	}
+}
+
+func TestOrderLineItemsAfterCancellingAnItem(t *testing.T) {
+	pb1 := models.TaxRule{ID: 1, Name: "PB1", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePB1, Rate: 10}
+	service := models.TaxRule{ID: 2, Name: "Service", Kind: models.TaxRuleKindService, Rate: 5.5}
+
+	order := pricedOrder(models.OrderTypeDineIn, []models.OrderItem{
+		{MenuItemID: 1, Quantity: 3, UnitPrice: 18333, Subtotal: 54999},
+		{MenuItemID: 2, Quantity: 2, UnitPrice: 45000, Subtotal: 90000},
+	}, pb1, service)
+	for i := range order.Charges {
+		order.Charges[i].ID = uint(i + 1)
+	}
+	placed := order.GrandTotal
+
+	// Cancelled by the kitchen after the order was priced
+	order.OrderItems[1].Status = models.OrderItemStatusCancelled
+	pricing.Reprice(&order, order.Charges)
+
+	if order.GrandTotal >= placed {
+		t.Fatalf("GrandTotal = %d after cancelling, was %d", order.GrandTotal, placed)
+	}
+	for i, charge := range order.Charges {
+		if charge.ID != uint(i+1) || charge.TaxRuleID == nil {
+			t.Errorf("charge %d lost its record: %+v", i, charge)
+		}
+	}
+
+	for _, tip := range []models.Money{0, 5000} {
+		lines := WithTip(OrderLineItems(&order), tip)
+		if total := LineItemsTotal(lines); total != order.GrandTotal+tip {
+			t.Errorf("tip %d: lines add up to %d, want %d", tip, total, order.GrandTotal+tip)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reconcileBatch caps the provider calls made in one pass over pending payments.
+const reconcileBatch = 100
+
+// ApplyFunc applies a provider status to the payments under its reference.
+// The reconciler uses the same one as the webhook.
+type ApplyFunc func(status *TransactionStatus) error
+
+// Reconciler catches up on gateway payments whose webhook never arrived and
+// writes a daily report of payments that disagree with the provider.
+type Reconciler struct {
+	db       *gorm.DB
+	provider PaymentProvider
+	apply    ApplyFunc
+	interval time.Duration
+	after    time.Duration
+}
+
+func NewReconciler(db *gorm.DB, provider PaymentProvider, apply ApplyFunc, interval, after time.Duration) *Reconciler {
+	return &Reconciler{
+		db:       db,
+		provider: provider,
+		apply:    apply,
+		interval: interval,
+		after:    after,
+	}
+}
+
+// Run checks pending payments every interval and reports on the previous day
+// once it has no report yet, until ctx is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := r.ReconcilePending(); err != nil {
+			log.Printf("Payment reconciliation failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Payment reconciliation updated %d payment(s)", n)
+		}
+
+		yesterday := time.Now().AddDate(0, 0, -1)
+		if due, err := r.reportDue(yesterday); err != nil {
+			log.Printf("Failed to check reconciliation report: %v", err)
+		} else if due {
+			report, err := r.Report(yesterday)
+			if err != nil {
+				log.Printf("Failed to create reconciliation report: %v", err)
+			} else {
+				log.Printf("Reconciliation report for %s: %d checked, %d mismatch(es)",
+					report.Date.Format("2006-01-02"), report.Checked, report.MismatchCount)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcilePending asks the provider about gateway payments that have been
+// pending for longer than the configured age and applies any change. A
+// payment the provider never saw fails once its checkout has expired. The
+// payments checked least recently go first, so ones that stay pending or
+// keep failing do not hold back the rest. It returns the number of
+// references updated.
+func (r *Reconciler) ReconcilePending() (int, error) {
+	var payments []models.Payment
+	err := r.db.
+		Where("method = ? AND provider = ? AND status = ? AND created_at < ?",
+			models.PaymentMethodGateway, r.provider.Name(), models.PaymentStatusPending, time.Now().Add(-r.after)).
+		Order("last_checked_at NULLS FIRST, created_at").
+		Limit(reconcileBatch).
+		Find(&payments).Error
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	checked := make(map[string]bool)
+	for i := range payments {
+		if err := r.markChecked(&payments[i]); err != nil {
+			return updated, err
+		}
+
+		reference, err := ProviderReference(r.db, &payments[i])
+		if err != nil {
+			log.Printf("Failed to find reference of payment %d: %v", payments[i].ID, err)
+			continue
+		}
+		if checked[reference] {
+			continue
+		}
+		checked[reference] = true
+
+		status, err := r.provider.GetTransactionStatus(reference)
+		if errors.Is(err, ErrTransactionNotFound) {
+			if time.Since(payments[i].CreatedAt) < TransactionExpiry {
+				continue
+			}
+			status = &TransactionStatus{
+				OrderID:        reference,
+				Status:         models.PaymentStatusFailed,
+				ProviderStatus: "not_found",
+				StatusMessage:  "Checkout expired without a transaction",
+			}
+		} else if err != nil {
+			log.Printf("Failed to check payment %s: %v", reference, err)
+			continue
+		}
+
+		if status.Status == models.PaymentStatusPending {
+			continue
+		}
+		if err := r.apply(status); err != nil {
+			log.Printf("Failed to apply status of payment %s: %v", reference, err)
+			continue
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// markChecked records that the payment was looked at in this pass, together
+// with the other payments of its session bill, which share its reference.
+func (r *Reconciler) markChecked(record *models.Payment) error {
+	query := r.db.Model(&models.Payment{})
+	if record.SessionBillID != nil {
+		query = query.Where("session_bill_id = ?", *record.SessionBillID)
+	} else {
+		query = query.Where("id = ?", record.ID)
+	}
+	return query.UpdateColumn("last_checked_at", time.Now()).Error
+}
+
+// Report compares the gateway payments created on the given day with the
+// provider and stores the mismatches. An existing report for the day is
+// replaced.
+func (r *Reconciler) Report(day time.Time) (*models.ReconciliationReport, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	var payments []models.Payment
+	err := r.db.
+		Where("method = ? AND provider = ? AND created_at >= ? AND created_at < ?",
+			models.PaymentMethodGateway, r.provider.Name(), start, end).
+		Order("created_at").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Payments on a session bill share one provider transaction
+	var references []string
+	byReference := make(map[string][]models.Payment)
+	for _, record := range payments {
+		reference, err := ProviderReference(r.db, &record)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := byReference[reference]; !ok {
+			references = append(references, reference)
+		}
+		byReference[reference] = append(byReference[reference], record)
+	}
+
+	report := models.ReconciliationReport{
+		Date:     start,
+		Provider: r.provider.Name(),
+		Checked:  len(payments),
+	}
+	for _, reference := range references {
+		report.Mismatches = append(report.Mismatches, r.compare(reference, byReference[reference])...)
+	}
+	report.MismatchCount = len(report.Mismatches)
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.ReconciliationReport
+		err := tx.Where("date = ?", start.Format("2006-01-02")).First(&existing).Error
+		if err == nil {
+			if err := tx.Where("report_id = ?", existing.ID).Delete(&models.ReconciliationMismatch{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&existing).Error; err != nil {
+				return err
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Create(&report).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// compare checks the payments made under one reference against the provider.
+func (r *Reconciler) compare(reference string, payments []models.Payment) []models.ReconciliationMismatch {
+	var local models.Money
+	for _, record := range payments {
+		local += record.GrossAmount
+	}
+
+	mismatch := func(record models.Payment, issue, detail string) models.ReconciliationMismatch {
+		return models.ReconciliationMismatch{
+			PaymentID:   record.ID,
+			Reference:   reference,
+			Issue:       issue,
+			LocalStatus: record.Status,
+			LocalAmount: record.GrossAmount,
+			Detail:      detail,
+		}
+	}
+
+	status, err := r.provider.GetTransactionStatus(reference)
+	if errors.Is(err, ErrTransactionNotFound) {
+		// A checkout that was never started only matches a failed payment
+		var mismatches []models.ReconciliationMismatch
+		for _, record := range payments {
+			if record.Status != models.PaymentStatusFailed {
+				mismatches = append(mismatches, mismatch(record, models.ReconciliationIssueMissing, "No transaction at the provider"))
+			}
+		}
+		return mismatches
+	}
+	if err != nil {
+		return []models.ReconciliationMismatch{mismatch(payments[0], models.ReconciliationIssueError, err.Error())}
+	}
+
+	var mismatches []models.ReconciliationMismatch
+	for _, record := range payments {
+		if record.Status != status.Status {
+			m := mismatch(record, models.ReconciliationIssueStatus,
+				fmt.Sprintf("Provider status %s", status.ProviderStatus))
+			m.ProviderStatus = status.Status
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	amount, err := strconv.ParseFloat(status.GrossAmount, 64)
+	if providerAmount := models.NewMoney(amount); err == nil && providerAmount != local {
+		m := mismatch(payments[0], models.ReconciliationIssueAmount,
+			fmt.Sprintf("%d payment(s) under this reference", len(payments)))
+		m.ProviderStatus = status.Status
+		m.LocalAmount = local
+		m.ProviderAmount = &providerAmount
+		mismatches = append(mismatches, m)
+	}
+
+	return mismatches
+}
+
+func (r *Reconciler) reportDue(day time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ReconciliationReport{}).
+		Where("date = ?", day.Format("2006-01-02")).
+		Count(&count).Error
+	return count == 0, err
+}
@@ -0,0 +1,221 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPaymentNotRefundable = errors.New("payment has not been settled")
+	ErrInvalidRefundAmount  = errors.New("refund amount exceeds the refundable balance")
+	ErrRefundFailed         = errors.New("refund was rejected by the payment gateway")
+)
+
+type RefundService struct {
+	db       *gorm.DB
+	provider PaymentProvider
+}
+
+func NewRefundService(db *gorm.DB, provider PaymentProvider) *RefundService {
+	return &RefundService{
+		db:       db,
+		provider: provider,
+	}
+}
+
+// Refund refunds amount of a payment, or the whole remaining balance when
+// amount is 0. The refund is recorded before the gateway call so the same
+// balance cannot be refunded twice; a rejected refund is kept as failed.
+// Manual payments are refunded without contacting the gateway.
+func (s *RefundService) Refund(paymentID uint, amount models.Money, reason string, staffID *uint) (*models.Refund, error) {
+	var payment models.Payment
+	var refund models.Refund
+	var reference string
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, paymentID).Error; err != nil {
+			return err
+		}
+
+		if payment.Status != models.PaymentStatusPaid && payment.Status != models.PaymentStatusPartiallyRefunded {
+			return ErrPaymentNotRefundable
+		}
+
+		reserved, err := sumRefunds(tx, payment.ID, models.RefundStatusPending, models.RefundStatusSucceeded)
+		if err != nil {
+			return err
+		}
+
+		amount, err = refundAmount(payment.GrossAmount, reserved, amount)
+		if err != nil {
+			return err
+		}
+
+		if reference, err = ProviderReference(tx, &payment); err != nil {
+			return err
+		}
+
+		refund = models.Refund{
+			PaymentID: payment.ID,
+			OrderID:   payment.OrderID,
+			RefundKey: fmt.Sprintf("%s-R%d", payment.MidtransOrderID, time.Now().UnixNano()),
+			Amount:    amount,
+			Reason:    reason,
+			Status:    models.RefundStatusPending,
+			StaffID:   staffID,
+		}
+		return tx.Create(&refund).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cash and EDC refunds are handed back at the counter
+	resp := &RefundResult{}
+	if payment.Method == models.PaymentMethodGateway {
+		resp, err = s.provider.RefundTransaction(reference, RefundRequest{
+			RefundKey: refund.RefundKey,
+			Amount:    refund.Amount,
+			Reason:    refund.Reason,
+		})
+	}
+	if err != nil {
+		message := err.Error()
+		refund.Status = models.RefundStatusFailed
+		refund.FailureMessage = &message
+		s.db.Save(&refund)
+		return &refund, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		refund.Status = models.RefundStatusSucceeded
+		if resp.ProviderRefundID != "" {
+			refund.ProviderRefundID = &resp.ProviderRefundID
+		}
+		if err := tx.Save(&refund).Error; err != nil {
+			return err
+		}
+
+		refunded, err := sumRefunds(tx, payment.ID, models.RefundStatusSucceeded)
+		if err != nil {
+			return err
+		}
+
+		paymentStatus, transactionStatus := refundStatus(payment.GrossAmount, refunded)
+		err = tx.Model(&payment).Updates(map[string]interface{}{
+			"status":             paymentStatus,
+			"transaction_status": transactionStatus,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// An order paid in shares is refunded once all of its payments are
+		orderStatus, err := orderRefundStatus(tx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Order{}).Where("id = ?", payment.OrderID).Update("payment_status", orderStatus).Error
+	})
+	if err != nil {
+		return &refund, fmt.Errorf("refund succeeded but could not be recorded: %w", err)
+	}
+
+	return &refund, nil
+}
+
+// GetRefunds lists a payment's refunds, newest first.
+func (s *RefundService) GetRefunds(paymentID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := s.db.Where("payment_id = ?", paymentID).Order("created_at DESC").Find(&refunds).Error
+	return refunds, err
+}
+
+// ProviderReference returns the order reference the provider knows a
+// payment by. Payments on a session bill share the bill's reference.
+func ProviderReference(db *gorm.DB, record *models.Payment) (string, error) {
+	if record.SessionBillID == nil {
+		return record.MidtransOrderID, nil
+	}
+
+	var bill models.SessionBill
+	if err := db.Select("reference").First(&bill, *record.SessionBillID).Error; err != nil {
+		return "", err
+	}
+	return bill.Reference, nil
+}
+
+// PaymentRefundStatus narrows a refund status reported for a shared
+// reference down to one payment under it. Each payment takes the status of
+// its own succeeded refunds; nil is returned when none of the refund was
+// made from it.
+func PaymentRefundStatus(db *gorm.DB, record *models.Payment, status *TransactionStatus) (*TransactionStatus, error) {
+	refunded, err := sumRefunds(db, record.ID, models.RefundStatusSucceeded)
+	if err != nil || refunded == 0 {
+		return nil, err
+	}
+
+	own := *status
+	own.Status, own.ProviderStatus = refundStatus(record.GrossAmount, refunded)
+	return &own, nil
+}
+
+func orderRefundStatus(tx *gorm.DB, orderID uint) (string, error) {
+	var paid models.Money
+	err := tx.Model(&models.Payment{}).
+		Select("COALESCE(SUM(gross_amount), 0)").
+		Where("order_id = ? AND status IN ?", orderID, []string{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded}).
+		Scan(&paid).Error
+	if err != nil {
+		return "", err
+	}
+
+	var refunded models.Money
+	err = tx.Model(&models.Refund{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("order_id = ? AND status = ?", orderID, models.RefundStatusSucceeded).
+		Scan(&refunded).Error
+	if err != nil {
+		return "", err
+	}
+
+	status, _ := refundStatus(paid, refunded)
+	return status, nil
+}
+
+func sumRefunds(tx *gorm.DB, paymentID uint, statuses ...string) (models.Money, error) {
+	var total models.Money
+	err := tx.Model(&models.Refund{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ? AND status IN ?", paymentID, statuses).
+		Scan(&total).Error
+	return total, err
+}
+
+// refundAmount resolves the amount to refund given what is already
+// refunded or in flight. A requested amount of 0 means the full balance.
+func refundAmount(gross, reserved, requested models.Money) (models.Money, error) {
+	balance := gross - reserved
+	if balance <= 0 || requested < 0 || requested > balance {
+		return 0, ErrInvalidRefundAmount
+	}
+
+	if requested == 0 {
+		return balance, nil
+	}
+	return requested, nil
+}
+
+// refundStatus returns the payment status and the gateway transaction
+// status after refunded out of gross has been returned.
+func refundStatus(gross, refunded models.Money) (string, string) {
+	if refunded >= gross {
+		return models.PaymentStatusRefunded, "refund"
+	}
+	return models.PaymentStatusPartiallyRefunded, "partial_refund"
+}
@@ -0,0 +1,284 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lendral3n/ordering-system/internal/config"
+	"lendral3n/ordering-system/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const stubServerKey = "SB-Mid-server-test"
+
+// newStubMidtrans starts a Core API stub that answers refund requests with
+// the given status code and returns a service pointed at it.
+func newStubMidtrans(t *testing.T, statusCode string) (*MidtransService, *[]map[string]interface{}) {
+	t.Helper()
+
+	var received []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/ORD-1/refund" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, _, ok := r.BasicAuth(); !ok || user != stubServerKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status_code":          statusCode,
+			"status_message":       "Success, refund request is approved",
+			"transaction_id":       "txn-1",
+			"order_id":             "ORD-1",
+			"gross_amount":         "50000.00",
+			"transaction_status":   "partial_refund",
+			"refund_chargeback_id": 42,
+			"refund_amount":        fmt.Sprintf("%v.00", body["amount"]),
+			"refund_key":           body["refund_key"],
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	svc := NewMidtransService(&config.Config{
+		MidtransServerKey: stubServerKey,
+		MidtransClientKey: "SB-Mid-client-test",
+		MidtransAPIURL:    srv.URL,
+	})
+	return svc, &received
+}
+
+func TestRefundTransactionAgainstStub(t *testing.T) {
+	svc, received := newStubMidtrans(t, "200")
+
+	resp, err := svc.RefundTransaction("ORD-1", RefundRequest{
+		RefundKey: "ORD-1-R1",
+		Amount:    models.NewMoney(12500.4),
+		Reason:    "Cold food",
+	})
+	if err != nil {
+		t.Fatalf("RefundTransaction: %v", err)
+	}
+
+	if resp.ProviderRefundID != "42" || resp.Amount != "12500.00" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if len(*received) != 1 {
+		t.Fatalf("stub received %d requests, want 1", len(*received))
+	}
+	body := (*received)[0]
+	if body["refund_key"] != "ORD-1-R1" || body["reason"] != "Cold food" {
+		t.Errorf("unexpected request body: %v", body)
+	}
+	if body["amount"] != float64(12500) {
+		t.Errorf("amount = %v, want 12500", body["amount"])
+	}
+}
+
+func TestRefundTransactionRejected(t *testing.T) {
+	svc, _ := newStubMidtrans(t, "412")
+
+	if _, err := svc.RefundTransaction("ORD-1", RefundRequest{RefundKey: "ORD-1-R2", Amount: 1000}); err == nil {
+		t.Fatal("expected an error for a rejected refund")
+	}
+}
+
+// newMockDB returns a GORM handle whose statements are checked against the
+// expectations set on the mock, in order.
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("open mock db: %v", err)
+	}
+	return db, mock
+}
+
+// expectPaymentLock expects payment 1 of order 7, paid 50000 through the
+// gateway as ORD-1, to be locked with reserved already refunded or in flight.
+func expectPaymentLock(mock sqlmock.Sqlmock, status string, reserved models.Money) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "payments" WHERE "payments"."id" = \$1 .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "method", "status", "midtrans_order_id", "gross_amount"}).
+			AddRow(1, 7, models.PaymentMethodGateway, status, "ORD-1", 50000))
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "refunds" WHERE payment_id = \$1 AND status IN \(\$2,\$3\)`).
+		WithArgs(1, models.RefundStatusPending, models.RefundStatusSucceeded).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(reserved)))
+}
+
+// expectRefund expects a refund of amount to be recorded, accepted by the
+// gateway and rolled up into the payment and order statuses.
+func expectRefund(mock sqlmock.Sqlmock, status string, reserved, amount models.Money, paymentStatus, transactionStatus string) {
+	expectPaymentLock(mock, status, reserved)
+	mock.ExpectQuery(`INSERT INTO "refunds"`).
+		WithArgs(1, 7, sqlmock.AnyArg(), amount, "Cold food", models.RefundStatusPending, nil, nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	refunded := reserved + amount
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "refunds" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "refunds" WHERE payment_id = \$1 AND status IN \(\$2\)`).
+		WithArgs(1, models.RefundStatusSucceeded).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(refunded)))
+	mock.ExpectExec(`UPDATE "payments" SET "status"=\$1,"transaction_status"=\$2`).
+		WithArgs(paymentStatus, transactionStatus, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(gross_amount\), 0\) FROM "payments" WHERE \(order_id = \$1 AND status IN`).
+		WithArgs(7, models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(50000)))
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "refunds" WHERE order_id = \$1 AND status = \$2`).
+		WithArgs(7, models.RefundStatusSucceeded).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(refunded)))
+	mock.ExpectExec(`UPDATE "orders" SET "payment_status"=\$1`).
+		WithArgs(paymentStatus, sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestRefundServicePartialThenFull(t *testing.T) {
+	svc, received := newStubMidtrans(t, "200")
+	db, mock := newMockDB(t)
+	refunds := NewRefundService(db, svc)
+
+	// Part of the bill, then the rest of it
+	expectRefund(mock, models.PaymentStatusPaid, 0, 20000, models.PaymentStatusPartiallyRefunded, "partial_refund")
+	expectRefund(mock, models.PaymentStatusPartiallyRefunded, 20000, 30000, models.PaymentStatusRefunded, "refund")
+
+	for _, amount := range []models.Money{20000, 0} {
+		refund, err := refunds.Refund(1, amount, "Cold food", nil)
+		if err != nil {
+			t.Fatalf("Refund(%d): %v", amount, err)
+		}
+		if refund.Status != models.RefundStatusSucceeded || refund.ProviderRefundID == nil {
+			t.Errorf("refund = %+v, want succeeded with the gateway's ID", refund)
+		}
+	}
+
+	if len(*received) != 2 {
+		t.Fatalf("gateway received %d refunds, want 2", len(*received))
+	}
+	for i, want := range []float64{20000, 30000} {
+		if got := (*received)[i]["amount"]; got != want {
+			t.Errorf("refund %d sent amount %v, want %v", i+1, got, want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRefundServiceRejectsOverRefund(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		reserved models.Money
+		amount   models.Money
+		want     error
+	}{
+		{"more than the balance", models.PaymentStatusPartiallyRefunded, 20000, 30001, ErrInvalidRefundAmount},
+		{"balance already in flight", models.PaymentStatusPaid, 50000, 0, ErrInvalidRefundAmount},
+		{"unsettled payment", models.PaymentStatusPending, 0, 0, ErrPaymentNotRefundable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, received := newStubMidtrans(t, "200")
+			db, mock := newMockDB(t)
+
+			if tt.want == ErrPaymentNotRefundable {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "payments"`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "status", "gross_amount"}).
+						AddRow(1, 7, tt.status, 50000))
+			} else {
+				expectPaymentLock(mock, tt.status, tt.reserved)
+			}
+			mock.ExpectRollback()
+
+			_, err := NewRefundService(db, svc).Refund(1, tt.amount, "Cold food", nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if len(*received) != 0 {
+				t.Errorf("gateway received %d refunds, want none", len(*received))
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRefundAmount(t *testing.T) {
+	tests := []struct {
+		name      string
+		gross     models.Money
+		reserved  models.Money
+		requested models.Money
+		want      models.Money
+		wantErr   bool
+	}{
+		{"full balance", 50000, 0, 0, 50000, false},
+		{"remaining balance", 50000, 20000, 0, 30000, false},
+		{"partial", 50000, 0, 15000, 15000, false},
+		{"rounds to whole rupiah", 50000, 0, models.NewMoney(15000.6), 15001, false},
+		{"exact remainder", 50000, 20000, 30000, 30000, false},
+		{"over balance", 50000, 20000, 30001, 0, true},
+		{"negative", 50000, 0, -1, 0, true},
+		{"nothing left", 50000, 50000, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := refundAmount(tt.gross, tt.reserved, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundStatus(t *testing.T) {
+	tests := []struct {
+		refunded    models.Money
+		wantOrder   string
+		wantGateway string
+	}{
+		{50000, models.PaymentStatusRefunded, "refund"},
+		{20000, models.PaymentStatusPartiallyRefunded, "partial_refund"},
+	}
+
+	for _, tt := range tests {
+		order, gateway := refundStatus(50000, tt.refunded)
+		if order != tt.wantOrder || gateway != tt.wantGateway {
+			t.Errorf("refundStatus(50000, %v) = %s, %s; want %s, %s", tt.refunded, order, gateway, tt.wantOrder, tt.wantGateway)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"bytes"
+
+	"github.com/SebastiaanKlippert/go-wkhtmltopdf"
+)
+
+type Options struct {
+	Dpi         uint
+	Orientation string
+	PageSize    string
+	// Margins in millimeters. Zero keeps the wkhtmltopdf defaults.
+	MarginTop    uint
+	MarginBottom uint
+	MarginLeft   uint
+	MarginRight  uint
+}
+
+// DefaultOptions is A4 portrait at 300 DPI.
+func DefaultOptions() Options {
+	return Options{
+		Dpi:         300,
+		Orientation: wkhtmltopdf.OrientationPortrait,
+		PageSize:    wkhtmltopdf.PageSizeA4,
+	}
+}
+
+// FromHTML converts an HTML document to PDF using the wkhtmltopdf binary.
+func FromHTML(html string, opts Options) ([]byte, error) {
+	pdfg, err := wkhtmltopdf.NewPDFGenerator()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set global options
+	pdfg.Dpi.Set(opts.Dpi)
+	pdfg.Orientation.Set(opts.Orientation)
+	pdfg.PageSize.Set(opts.PageSize)
+
+	if opts.MarginTop > 0 {
+		pdfg.MarginTop.Set(opts.MarginTop)
+	}
+	if opts.MarginBottom > 0 {
+		pdfg.MarginBottom.Set(opts.MarginBottom)
+	}
+	if opts.MarginLeft > 0 {
+		pdfg.MarginLeft.Set(opts.MarginLeft)
+	}
+	if opts.MarginRight > 0 {
+		pdfg.MarginRight.Set(opts.MarginRight)
+	}
+
+	// Create page from HTML
+	page := wkhtmltopdf.NewPageReader(bytes.NewReader([]byte(html)))
+	pdfg.AddPage(page)
+
+	// Create PDF
+	if err := pdfg.Create(); err != nil {
+		return nil, err
+	}
+
+	return pdfg.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+package pricing
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rules returns the tax and service charge rules in effect at t, taxes
+// first. Until the first rule is created, the configured tax and service
+// percentages apply as exclusive rules so existing setups keep pricing the
+// same way.
+func Rules(db *gorm.DB, at time.Time, taxPercentage, servicePercentage float64) ([]models.TaxRule, error) {
+	var rules []models.TaxRule
+	err := db.
+		Where("is_active = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", true, at, at).
+		Order("kind DESC, id").
+		Find(&rules).Error
+	if err != nil || len(rules) > 0 {
+		return rules, err
+	}
+
+	var count int64
+	if err := db.Unscoped().Model(&models.TaxRule{}).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, nil
+	}
+	return DefaultRules(taxPercentage, servicePercentage), nil
+}
+
+// DefaultRules builds unsaved rules from the configured percentages.
+func DefaultRules(taxPercentage, servicePercentage float64) []models.TaxRule {
+	var rules []models.TaxRule
+	if taxPercentage > 0 {
+		rules = append(rules, models.TaxRule{Name: "Tax", Kind: models.TaxRuleKindTax, Rate: taxPercentage, IsActive: true})
+	}
+	if servicePercentage > 0 {
+		rules = append(rules, models.TaxRule{Name: "Service Charge", Kind: models.TaxRuleKindService, Rate: servicePercentage, IsActive: true})
+	}
+	return rules
+}
+
+// Apply prices an order from its items under the given rules and records
+// each rule that applied as a charge on the order.
+//
+// Taxes are charged on the items that are not tax exempt, service charges
+// on all items. Every charge is rounded once, to the nearest rupiah, on the
+// order as a whole. An inclusive tax is already part of the menu prices, so
+// it is only worked out for the bill and not added to the grand total.
+func Apply(order *models.Order, rules []models.TaxRule) {
+	var subtotal, taxable models.Money
+	for _, item := range order.OrderItems {
+		if item.Status == models.OrderItemStatusCancelled {
+			continue
+		}
+		subtotal += ItemBase(models.TaxRuleKindService, item)
+		taxable += ItemBase(models.TaxRuleKindTax, item)
+	}
+
+	order.TotalAmount = subtotal
+	order.TaxRate, order.TaxAmount, order.IncludedTax = 0, 0, 0
+	order.ServiceRate, order.ServiceCharge = 0, 0
+	order.Charges = nil
+
+	for _, rule := range rules {
+		if !rule.AppliesTo(order.OrderType) {
+			continue
+		}
+
+		charge := models.OrderCharge{
+			Name:      rule.Name,
+			Kind:      rule.Kind,
+			TaxType:   rule.TaxType,
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+			Base:      subtotal,
+		}
+		if rule.ID != 0 {
+			ruleID := rule.ID
+			charge.TaxRuleID = &ruleID
+		}
+
+		switch {
+		case rule.Kind == models.TaxRuleKindService:
+			charge.Inclusive = false
+			charge.Amount = subtotal.Percent(rule.Rate)
+			order.ServiceRate += rule.Rate
+			order.ServiceCharge += charge.Amount
+		case rule.Inclusive:
+			charge.Base = taxable
+			charge.Amount = models.NewMoney(taxable.Float64() * rule.Rate / (100 + rule.Rate))
+			order.IncludedTax += charge.Amount
+		default:
+			charge.Base = taxable
+			charge.Amount = taxable.Percent(rule.Rate)
+			order.TaxRate += rule.Rate
+			order.TaxAmount += charge.Amount
+		}
+
+		order.Charges = append(order.Charges, charge)
+	}
+
+	order.GrandTotal = order.TotalAmount + order.TaxAmount + order.ServiceCharge
+}
+
+// ItemBase returns the part of an item a charge of the given kind is worked
+// out on: taxes skip tax exempt items, service charges cover every item.
+func ItemBase(kind string, item models.OrderItem) models.Money {
+	if kind == models.TaxRuleKindTax && item.TaxExempt {
+		return 0
+	}
+	return item.Subtotal
+}
+
+// Reprice prices an order again from its items under the rules it was
+// placed with, recorded as its charges, so cancelling an item lowers the
+// bill without picking up rules that changed since. The charges keep their
+// IDs. Orders priced before charges were recorded use their stored rates.
+func Reprice(order *models.Order, charges []models.OrderCharge) {
+	rules := make([]models.TaxRule, 0, len(charges))
+	for _, charge := range charges {
+		rule := models.TaxRule{
+			Name:      charge.Name,
+			Kind:      charge.Kind,
+			TaxType:   charge.TaxType,
+			Rate:      charge.Rate,
+			Inclusive: charge.Inclusive,
+			IsActive:  true,
+		}
+		if charge.TaxRuleID != nil {
+			rule.ID = *charge.TaxRuleID
+		}
+		rules = append(rules, rule)
+	}
+	if len(charges) == 0 {
+		rules = DefaultRules(order.EffectiveTaxRate(), order.EffectiveServiceRate())
+	}
+
+	Apply(order, rules)
+
+	for i := range order.Charges {
+		order.Charges[i].OrderID = order.ID
+		if i < len(charges) {
+			order.Charges[i].ID = charges[i].ID
+			order.Charges[i].CreatedAt = charges[i].CreatedAt
+		}
+	}
+}
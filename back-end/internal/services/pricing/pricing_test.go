@@ -0,0 +1,169 @@
+package pricing
+
+import (
+	"lendral3n/ordering-system/internal/models"
+	"testing"
+)
+
+var (
+	pb1     = models.TaxRule{ID: 1, Name: "PB1", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePB1, Rate: 10}
+	ppn     = models.TaxRule{ID: 2, Name: "PPN", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePPN, Rate: 11, Inclusive: true}
+	service = models.TaxRule{ID: 3, Name: "Service", Kind: models.TaxRuleKindService, Rate: 5}
+	dineIn  = models.TaxRule{ID: 4, Name: "Service", Kind: models.TaxRuleKindService, Rate: 5, DineInOnly: true}
+)
+
+// testItems is a taxable dish and a tax exempt bottle of water.
+func testItems() []models.OrderItem {
+	return []models.OrderItem{
+		{Quantity: 2, UnitPrice: 25000, Subtotal: 50000},
+		{Quantity: 1, UnitPrice: 20000, Subtotal: 20000, TaxExempt: true},
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name          string
+		orderType     string
+		items         []models.OrderItem
+		rules         []models.TaxRule
+		total         models.Money
+		tax           models.Money
+		includedTax   models.Money
+		serviceCharge models.Money
+		grandTotal    models.Money
+		charges       int
+	}{
+		{
+			name:      "no rules",
+			orderType: models.OrderTypeDineIn,
+			items:     testItems(),
+			total:     70000, grandTotal: 70000,
+		},
+		{
+			name:      "exclusive tax skips exempt items",
+			orderType: models.OrderTypeDineIn,
+			items:     testItems(),
+			rules:     []models.TaxRule{pb1, service},
+			total:     70000, tax: 5000, serviceCharge: 3500, grandTotal: 78500,
+			charges: 2,
+		},
+		{
+			name:      "inclusive tax is not added",
+			orderType: models.OrderTypeDineIn,
+			items:     testItems(),
+			rules:     []models.TaxRule{ppn, service},
+			total:     70000, includedTax: 4955, serviceCharge: 3500, grandTotal: 73500,
+			charges: 2,
+		},
+		{
+			name:      "exclusive and inclusive together",
+			orderType: models.OrderTypeDineIn,
+			items:     testItems(),
+			rules:     []models.TaxRule{pb1, ppn},
+			total:     70000, tax: 5000, includedTax: 4955, grandTotal: 75000,
+			charges: 2,
+		},
+		{
+			name:      "takeaway skips dine in only rules",
+			orderType: models.OrderTypeTakeaway,
+			items:     testItems(),
+			rules:     []models.TaxRule{pb1, dineIn},
+			total:     70000, tax: 5000, grandTotal: 75000,
+			charges: 1,
+		},
+		{
+			name:      "dine in pays dine in only rules",
+			orderType: models.OrderTypeDineIn,
+			items:     testItems(),
+			rules:     []models.TaxRule{pb1, dineIn},
+			total:     70000, tax: 5000, serviceCharge: 3500, grandTotal: 78500,
+			charges: 2,
+		},
+		{
+			name:      "cancelled items are not charged",
+			orderType: models.OrderTypeDineIn,
+			items: append(testItems(), models.OrderItem{
+				Quantity: 1, UnitPrice: 30000, Subtotal: 30000, Status: models.OrderItemStatusCancelled,
+			}),
+			rules: []models.TaxRule{pb1, service},
+			total: 70000, tax: 5000, serviceCharge: 3500, grandTotal: 78500,
+			charges: 2,
+		},
+		{
+			name:      "rounds once per order",
+			orderType: models.OrderTypeDineIn,
+			items: []models.OrderItem{
+				{Quantity: 1, UnitPrice: 3333, Subtotal: 3333},
+				{Quantity: 1, UnitPrice: 3333, Subtotal: 3333},
+				{Quantity: 1, UnitPrice: 3333, Subtotal: 3333},
+			},
+			rules: []models.TaxRule{pb1},
+			total: 9999, tax: 1000, grandTotal: 10999,
+			charges: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := models.Order{OrderType: tt.orderType, OrderItems: tt.items}
+			Apply(&order, tt.rules)
+
+			if order.TotalAmount != tt.total {
+				t.Errorf("TotalAmount = %d, want %d", order.TotalAmount, tt.total)
+			}
+			if order.TaxAmount != tt.tax {
+				t.Errorf("TaxAmount = %d, want %d", order.TaxAmount, tt.tax)
+			}
+			if order.IncludedTax != tt.includedTax {
+				t.Errorf("IncludedTax = %d, want %d", order.IncludedTax, tt.includedTax)
+			}
+			if order.ServiceCharge != tt.serviceCharge {
+				t.Errorf("ServiceCharge = %d, want %d", order.ServiceCharge, tt.serviceCharge)
+			}
+			if order.GrandTotal != tt.grandTotal {
+				t.Errorf("GrandTotal = %d, want %d", order.GrandTotal, tt.grandTotal)
+			}
+			if len(order.Charges) != tt.charges {
+				t.Errorf("recorded %d charges, want %d", len(order.Charges), tt.charges)
+			}
+		})
+	}
+}
+
+func TestApplyRecordsCharges(t *testing.T) {
+	order := models.Order{OrderType: models.OrderTypeDineIn, OrderItems: testItems()}
+	Apply(&order, []models.TaxRule{pb1, ppn, service})
+
+	want := []models.OrderCharge{
+		{Name: "PB1", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePB1, Rate: 10, Base: 50000, Amount: 5000},
+		{Name: "PPN", Kind: models.TaxRuleKindTax, TaxType: models.TaxTypePPN, Rate: 11, Inclusive: true, Base: 50000, Amount: 4955},
+		{Name: "Service", Kind: models.TaxRuleKindService, Rate: 5, Base: 70000, Amount: 3500},
+	}
+	if len(order.Charges) != len(want) {
+		t.Fatalf("recorded %d charges, want %d", len(order.Charges), len(want))
+	}
+	for i, charge := range order.Charges {
+		if charge.TaxRuleID == nil {
+			t.Errorf("charge %s has no rule", charge.Name)
+		}
+		charge.TaxRuleID = nil
+		if charge != want[i] {
+			t.Errorf("charge %d = %+v, want %+v", i, charge, want[i])
+		}
+	}
+}
+
+func TestDefaultRulesPriceAsBefore(t *testing.T) {
+	order := models.Order{OrderType: models.OrderTypeTakeaway, OrderItems: testItems()}
+	Apply(&order, DefaultRules(10, 5))
+
+	// The configured percentages applied to takeaway orders too
+	if order.TaxAmount != 5000 || order.ServiceCharge != 3500 || order.GrandTotal != 78500 {
+		t.Errorf("got tax %d, service %d, total %d", order.TaxAmount, order.ServiceCharge, order.GrandTotal)
+	}
+	for _, charge := range order.Charges {
+		if charge.TaxRuleID != nil {
+			t.Errorf("default charge %s points at rule %d", charge.Name, *charge.TaxRuleID)
+		}
+	}
+}
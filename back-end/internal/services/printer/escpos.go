@@ -0,0 +1,158 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Paper is the roll width of a thermal printer in millimetres.
+type Paper int
+
+const (
+	Paper58 Paper = 58
+	Paper80 Paper = 80
+)
+
+// Columns is the number of characters per line in the default font.
+func (p Paper) Columns() int {
+	if p == Paper58 {
+		return 32
+	}
+	return 48
+}
+
+// ParsePaper accepts "58", "80" or "58mm"/"80mm". An empty string yields fallback.
+func ParsePaper(value string, fallback Paper) (Paper, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(strings.ToLower(value)), "mm")
+	if value == "" {
+		return fallback, nil
+	}
+
+	width, err := strconv.Atoi(value)
+	if err != nil || (Paper(width) != Paper58 && Paper(width) != Paper80) {
+		return 0, fmt.Errorf("unsupported paper width %q", value)
+	}
+
+	return Paper(width), nil
+}
+
+// ESC/POS control bytes
+const (
+	esc = 0x1B
+	gs  = 0x1D
+)
+
+// Alignment values for ESC a
+const (
+	AlignLeft   byte = 0
+	AlignCenter byte = 1
+	AlignRight  byte = 2
+)
+
+// Document builds an ESC/POS byte stream for a given paper width.
+type Document struct {
+	buf   bytes.Buffer
+	paper Paper
+}
+
+// NewDocument starts a document with the printer reset to its defaults.
+func NewDocument(paper Paper) *Document {
+	d := &Document{paper: paper}
+	d.buf.Write([]byte{esc, '@'})
+	return d
+}
+
+func (d *Document) Columns() int {
+	return d.paper.Columns()
+}
+
+func (d *Document) Align(align byte) *Document {
+	d.buf.Write([]byte{esc, 'a', align})
+	return d
+}
+
+func (d *Document) Bold(on bool) *Document {
+	d.buf.Write([]byte{esc, 'E', boolByte(on)})
+	return d
+}
+
+// DoubleSize doubles both character width and height, halving Columns.
+func (d *Document) DoubleSize(on bool) *Document {
+	size := byte(0x00)
+	if on {
+		size = 0x11
+	}
+	d.buf.Write([]byte{gs, '!', size})
+	return d
+}
+
+// Line writes text followed by a line feed.
+func (d *Document) Line(text string) *Document {
+	d.buf.WriteString(sanitize(text))
+	d.buf.WriteByte('\n')
+	return d
+}
+
+// Columns2 writes left and right aligned text on one line, wrapping the
+// left side when both do not fit.
+func (d *Document) Columns2(left, right string) *Document {
+	width := d.Columns()
+	left, right = sanitize(left), sanitize(right)
+
+	space := width - utf8.RuneCountInString(right) - 1
+	for utf8.RuneCountInString(left) > space && space > 0 {
+		runes := []rune(left)
+		d.Line(string(runes[:space]))
+		left = string(runes[space:])
+	}
+
+	padding := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if padding < 1 {
+		padding = 1
+	}
+	return d.Line(left + strings.Repeat(" ", padding) + right)
+}
+
+// Divider draws a full-width dashed line.
+func (d *Document) Divider() *Document {
+	return d.Line(strings.Repeat("-", d.Columns()))
+}
+
+func (d *Document) Feed(lines int) *Document {
+	d.buf.Write([]byte{esc, 'd', byte(lines)})
+	return d
+}
+
+// Cut feeds past the tear bar and performs a partial cut.
+func (d *Document) Cut() *Document {
+	d.buf.Write([]byte{gs, 'V', 'B', 0x00})
+	return d
+}
+
+func (d *Document) Bytes() []byte {
+	return d.buf.Bytes()
+}
+
+// sanitize keeps the stream printable on the default code page by
+// replacing anything outside printable ASCII.
+func sanitize(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+		if r < 0x20 || r > 0x7E {
+			return '?'
+		}
+		return r
+	}, text)
+}
+
+func boolByte(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package printer
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sink delivers a rendered print job to a printer. The job name is used by
+// sinks that keep a copy of each job, such as spool directories.
+type Sink interface {
+	Print(job string, data []byte) error
+}
+
+// NewSink parses a printer target:
+//
+//	tcp://192.168.1.50:9100  raw socket (JetDirect) printer
+//	192.168.1.50             same, on the default port 9100
+//	file:///dev/usb/lp0      device or file, jobs are appended
+//	file:///var/spool/pos    existing directory, one file per job
+//
+// An empty target returns a nil Sink, meaning printing is disabled.
+func NewSink(target string) (Sink, error) {
+	target = strings.TrimSpace(target)
+	switch {
+	case target == "":
+		return nil, nil
+	case strings.HasPrefix(target, "file://"):
+		return NewFileSink(strings.TrimPrefix(target, "file://")), nil
+	case strings.HasPrefix(target, "tcp://"):
+		return NewNetworkSink(strings.TrimPrefix(target, "tcp://")), nil
+	case strings.Contains(target, "://"):
+		return nil, fmt.Errorf("unsupported printer target %q", target)
+	default:
+		return NewNetworkSink(target), nil
+	}
+}
+
+type NetworkSink struct {
+	addr    string
+	timeout time.Duration
+}
+
+// NewNetworkSink prints to a raw TCP printer, defaulting to port 9100.
+func NewNetworkSink(addr string) *NetworkSink {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "9100")
+	}
+	return &NetworkSink{addr: addr, timeout: 5 * time.Second}
+}
+
+func (s *NetworkSink) Print(job string, data []byte) error {
+	conn, err := net.DialTimeout("tcp", s.addr, s.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to printer %s: %w", s.addr, err)
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send job to printer %s: %w", s.addr, err)
+	}
+
+	return nil
+}
+
+type FileSink struct {
+	path string
+}
+
+// NewFileSink writes jobs to a device node or file, or as separate files
+// when path is a directory.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// NewSpoolSink writes each job as a separate file in dir, creating the
+// directory if needed.
+func NewSpoolSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	return NewFileSink(dir), nil
+}
+
+func (s *FileSink) Print(job string, data []byte) error {
+	target := s.path
+	if info, err := os.Stat(s.path); err == nil && info.IsDir() {
+		name := fmt.Sprintf("%s-%s.bin", time.Now().Format("20060102-150405.000"), sanitizeJobName(job))
+		target = filepath.Join(s.path, name)
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open printer file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write printer file: %w", err)
+	}
+
+	return nil
+}
+
+func sanitizeJobName(job string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, job)
+}
@@ -9,18 +9,37 @@ import (
 )
 
 type QRService struct {
-	baseURL string
+	baseURL       string
+	signer        *TableTokenSigner
+	sheetRenderer SheetRenderer
 }
 
-func NewService(baseURL string) *QRService {
+func NewService(baseURL string, signer *TableTokenSigner, sheetRenderer SheetRenderer) *QRService {
 	return &QRService{
-		baseURL: baseURL,
+		baseURL:       baseURL,
+		signer:        signer,
+		sheetRenderer: sheetRenderer,
 	}
 }
 
+// TableScanURL is the URL encoded in a table's QR code. The token proves the
+// scan came from the printed code rather than a hand-edited URL.
+func (s *QRService) TableScanURL(tableNumber string) string {
+	return fmt.Sprintf("%s/scan?table=%s&token=%s",
+		s.baseURL,
+		url.QueryEscape(tableNumber),
+		url.QueryEscape(s.signer.Sign(tableNumber)),
+	)
+}
+
+// VerifyTableToken returns the table number the token was signed for.
+func (s *QRService) VerifyTableToken(token string) (string, error) {
+	return s.signer.Verify(token)
+}
+
 func (s *QRService) GenerateTableQRCode(tableNumber string) ([]byte, error) {
 	// Create URL for scanning
-	scanURL := fmt.Sprintf("%s/scan?table=%s", s.baseURL, url.QueryEscape(tableNumber))
+	scanURL := s.TableScanURL(tableNumber)
 
 	// Generate QR code
 	qrCode, err := qr.Encode(scanURL, qr.Medium, 512)
@@ -0,0 +1,107 @@
+package qrcode
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+
+	qr "github.com/skip2/go-qrcode"
+)
+
+// EMVCo merchant presented QR tags used by QRIS
+const (
+	qrisTagFormat    = "00"
+	qrisTagInitiated = "01" // 11 static, 12 dynamic
+	qrisTagAmount    = "54"
+	qrisTagName      = "59"
+	qrisTagCity      = "60"
+	qrisTagCRC       = "63"
+
+	qrisDynamic = "12"
+)
+
+var ErrInvalidQRIS = errors.New("invalid QRIS payload")
+
+// QRIS is the part of an EMVCo QRIS payload shown to the customer.
+type QRIS struct {
+	MerchantName string
+	MerchantCity string
+	Amount       float64 // 0 when the payer enters the amount
+	Dynamic      bool    // single use, generated for one transaction
+}
+
+// ParseQRIS decodes an EMVCo QRIS payload and verifies its checksum.
+func ParseQRIS(payload string) (*QRIS, error) {
+	fields := make(map[string]string)
+	for i := 0; i < len(payload); {
+		if i+4 > len(payload) {
+			return nil, fmt.Errorf("%w: truncated field at %d", ErrInvalidQRIS, i)
+		}
+		tag := payload[i : i+2]
+		length, err := strconv.Atoi(payload[i+2 : i+4])
+		if err != nil || i+4+length > len(payload) {
+			return nil, fmt.Errorf("%w: bad length for tag %s", ErrInvalidQRIS, tag)
+		}
+		fields[tag] = payload[i+4 : i+4+length]
+
+		// The CRC is always the last field and covers everything before its value
+		if tag == qrisTagCRC {
+			if i+4+length != len(payload) {
+				return nil, fmt.Errorf("%w: data after CRC", ErrInvalidQRIS)
+			}
+			if want := qrisCRC(payload[:i+4]); fields[tag] != want {
+				return nil, fmt.Errorf("%w: CRC %s, expected %s", ErrInvalidQRIS, fields[tag], want)
+			}
+		}
+		i += 4 + length
+	}
+
+	if fields[qrisTagFormat] != "01" {
+		return nil, fmt.Errorf("%w: unsupported payload format", ErrInvalidQRIS)
+	}
+	if _, ok := fields[qrisTagCRC]; !ok {
+		return nil, fmt.Errorf("%w: missing CRC", ErrInvalidQRIS)
+	}
+
+	qris := &QRIS{
+		MerchantName: fields[qrisTagName],
+		MerchantCity: fields[qrisTagCity],
+		Dynamic:      fields[qrisTagInitiated] == qrisDynamic,
+	}
+	if amount, ok := fields[qrisTagAmount]; ok {
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad amount %q", ErrInvalidQRIS, amount)
+		}
+		qris.Amount = value
+	}
+
+	return qris, nil
+}
+
+// QRISDataURI renders a QRIS payload as a PNG data URI for display.
+func QRISDataURI(payload string) (string, error) {
+	png, err := qr.Encode(payload, qr.Medium, 512)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// qrisCRC is the CRC-16/CCITT-FALSE checksum EMVCo uses, as four
+// uppercase hex digits.
+func qrisCRC(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
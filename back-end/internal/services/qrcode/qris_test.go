@@ -0,0 +1,70 @@
+package qrcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// dynamicQRIS is a dynamic QRIS for 78500 as an acquirer returns it.
+const dynamicQRIS = "00020101021226660014ID.CO.QRIS.WWW01189360091500000000170215ID10243456789010303UMI" +
+	"51440014ID.CO.QRIS.WWW0215ID10243456789010303UMI5204581253033605405785005802ID" +
+	"5913WARUNG LENDRA6007JAKARTA61051219062210117ORD-20261016-000163046990"
+
+// withCRC appends a CRC field that matches body.
+func withCRC(body string) string {
+	return body + "6304" + qrisCRC(body+"6304")
+}
+
+func TestQRISCRC(t *testing.T) {
+	// Check value of CRC-16/CCITT-FALSE
+	if got := qrisCRC("123456789"); got != "29B1" {
+		t.Errorf("qrisCRC(123456789) = %s, want 29B1", got)
+	}
+	if got := qrisCRC(dynamicQRIS[:len(dynamicQRIS)-4]); got != "6990" {
+		t.Errorf("qrisCRC(payload) = %s, want 6990", got)
+	}
+}
+
+func TestParseQRIS(t *testing.T) {
+	qris, err := ParseQRIS(dynamicQRIS)
+	if err != nil {
+		t.Fatalf("ParseQRIS: %v", err)
+	}
+	want := QRIS{MerchantName: "WARUNG LENDRA", MerchantCity: "JAKARTA", Amount: 78500, Dynamic: true}
+	if *qris != want {
+		t.Errorf("ParseQRIS = %+v, want %+v", *qris, want)
+	}
+
+	static, err := ParseQRIS(withCRC("000201010211" + "5913WARUNG LENDRA6007JAKARTA"))
+	if err != nil {
+		t.Fatalf("ParseQRIS static: %v", err)
+	}
+	if static.Dynamic || static.Amount != 0 {
+		t.Errorf("static QRIS = %+v, want no amount", *static)
+	}
+}
+
+func TestParseQRISRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"bad CRC", strings.TrimSuffix(dynamicQRIS, "6990") + "6991"},
+		{"data after CRC", dynamicQRIS + "0000"},
+		{"truncated length", strings.Replace(dynamicQRIS, "5913WARUNG", "5999WARUNG", 1)},
+		{"truncated field", "0002010102125"},
+		{"bad amount", withCRC("00020101021254057850A5913WARUNG LENDRA")},
+		{"missing CRC", "0002010102125405785005913WARUNG LENDRA"},
+		{"unsupported format", withCRC("000202010212")},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if qris, err := ParseQRIS(tt.payload); !errors.Is(err, ErrInvalidQRIS) {
+				t.Errorf("ParseQRIS = %+v, %v, want ErrInvalidQRIS", qris, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/invoice"
+	"lendral3n/ordering-system/internal/services/pdf"
+)
+
+// SheetLayout controls how many table tents fit on one A4 page.
+type SheetLayout struct {
+	Columns int
+	Rows    int
+}
+
+func DefaultSheetLayout() SheetLayout {
+	return SheetLayout{Columns: 2, Rows: 3}
+}
+
+// Sheet is a grid of table codes laid out onto pages, ready to render.
+type Sheet struct {
+	Layout SheetLayout
+	Pages  [][][]SheetCell // pages -> rows -> cells
+	QRSize float64         // mm, sized so the grid fits an A4 page
+}
+
+type SheetCell struct {
+	TableNumber string
+	Capacity    int
+	QRCode      []byte // PNG
+}
+
+// SheetRenderer turns a table QR sheet into a PDF document.
+type SheetRenderer interface {
+	Render(sheet Sheet) ([]byte, error)
+}
+
+// NewSheetRenderer builds the sheet renderer for the configured invoice
+// renderer kind, so both PDFs need the same tools installed.
+func NewSheetRenderer(kind string) (SheetRenderer, error) {
+	switch kind {
+	case invoice.RendererNative, "":
+		return nativeSheetRenderer{}, nil
+	case invoice.RendererWkhtmltopdf:
+		return htmlSheetRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown sheet renderer %q", kind)
+	}
+}
+
+// GenerateTableSheetPDF renders the QR codes of the given tables onto
+// printable A4 pages laid out as a grid.
+func (s *QRService) GenerateTableSheetPDF(tables []models.Table, layout SheetLayout) ([]byte, error) {
+	if layout.Columns <= 0 || layout.Rows <= 0 {
+		return nil, fmt.Errorf("invalid sheet layout %dx%d", layout.Columns, layout.Rows)
+	}
+
+	cells := make([]SheetCell, 0, len(tables))
+	for _, table := range tables {
+		qrCode, err := s.GenerateTableQRCode(table.TableNumber)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.TableNumber, err)
+		}
+		cells = append(cells, SheetCell{
+			TableNumber: table.TableNumber,
+			Capacity:    table.Capacity,
+			QRCode:      qrCode,
+		})
+	}
+
+	// Size the QR so the grid fits an A4 page (~190x277mm printable)
+	qrSize := 150 / layout.Columns
+	if byHeight := 200 / layout.Rows; byHeight < qrSize {
+		qrSize = byHeight
+	}
+
+	return s.sheetRenderer.Render(Sheet{
+		Layout: layout,
+		Pages:  paginateCells(cells, layout),
+		QRSize: float64(qrSize),
+	})
+}
+
+func paginateCells(cells []SheetCell, layout SheetLayout) [][][]SheetCell {
+	perPage := layout.Columns * layout.Rows
+	pages := make([][][]SheetCell, 0, (len(cells)+perPage-1)/perPage)
+
+	for start := 0; start < len(cells); start += perPage {
+		end := start + perPage
+		if end > len(cells) {
+			end = len(cells)
+		}
+
+		rows := make([][]SheetCell, 0, layout.Rows)
+		for r := start; r < end; r += layout.Columns {
+			rowEnd := r + layout.Columns
+			if rowEnd > end {
+				rowEnd = end
+			}
+			rows = append(rows, cells[r:rowEnd])
+		}
+		pages = append(pages, rows)
+	}
+
+	return pages
+}
+
+type htmlSheetCell struct {
+	TableNumber string
+	Capacity    int
+	QRCode      template.URL
+}
+
+type htmlSheetData struct {
+	Pages     [][][]htmlSheetCell
+	CellWidth string
+	QRSize    string
+}
+
+// htmlSheetRenderer lays the sheet out as HTML and prints it through
+// wkhtmltopdf.
+type htmlSheetRenderer struct{}
+
+func (htmlSheetRenderer) Render(sheet Sheet) ([]byte, error) {
+	data := htmlSheetData{
+		Pages:     make([][][]htmlSheetCell, len(sheet.Pages)),
+		CellWidth: fmt.Sprintf("%.2f%%", 100/float64(sheet.Layout.Columns)),
+		QRSize:    fmt.Sprintf("%gmm", sheet.QRSize),
+	}
+	for p, page := range sheet.Pages {
+		data.Pages[p] = make([][]htmlSheetCell, len(page))
+		for r, row := range page {
+			for _, cell := range row {
+				data.Pages[p][r] = append(data.Pages[p][r], htmlSheetCell{
+					TableNumber: cell.TableNumber,
+					Capacity:    cell.Capacity,
+					QRCode:      template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(cell.QRCode)),
+				})
+			}
+		}
+	}
+
+	html, err := renderSheetHTML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate HTML: %w", err)
+	}
+
+	opts := pdf.DefaultOptions()
+	opts.MarginTop, opts.MarginBottom, opts.MarginLeft, opts.MarginRight = 10, 10, 10, 10
+
+	return pdf.FromHTML(html, opts)
+}
+
+func renderSheetHTML(data htmlSheetData) (string, error) {
+	tmplStr := `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; }
+        .page { page-break-after: always; }
+        .page:last-child { page-break-after: auto; }
+        table { width: 100%; border-collapse: collapse; table-layout: fixed; }
+        td { width: {{.CellWidth}}; border: 1px dashed #999; padding: 6mm 2mm; text-align: center; vertical-align: top; }
+        .qr { width: {{.QRSize}}; height: {{.QRSize}}; }
+        .table-number { font-size: 22pt; font-weight: bold; margin-top: 2mm; }
+        .capacity { font-size: 11pt; color: #444; }
+        .hint { font-size: 10pt; color: #666; margin-top: 1mm; }
+    </style>
+</head>
+<body>
+    {{range .Pages}}
+    <div class="page">
+        <table>
+            {{range .}}
+            <tr>
+                {{range .}}
+                <td>
+                    <img class="qr" src="{{.QRCode}}">
+                    <div class="table-number">Table {{.TableNumber}}</div>
+                    <div class="capacity">{{.Capacity}} seats</div>
+                    <div class="hint">Scan to view the menu and order</div>
+                </td>
+                {{end}}
+            </tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+</body>
+</html>
+`
+
+	tmpl, err := template.New("qr-sheet").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,105 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Sheet page geometry in mm
+const (
+	sheetMargin  = 10.0
+	sheetPadding = 3.0
+	sheetTextGap = 1.0
+)
+
+// nativeSheetRenderer draws the sheet directly with a pure-Go PDF library,
+// so it works without wkhtmltopdf installed.
+type nativeSheetRenderer struct{}
+
+func (nativeSheetRenderer) Render(sheet Sheet) ([]byte, error) {
+	doc := gofpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(sheetMargin, sheetMargin, sheetMargin)
+	doc.SetAutoPageBreak(false, 0)
+
+	// Core fonts are cp1252; translate so table names with accents survive
+	tr := doc.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := doc.GetPageSize()
+	cellWidth := (pageWidth - 2*sheetMargin) / float64(sheet.Layout.Columns)
+	cellHeight := (pageHeight - 2*sheetMargin) / float64(sheet.Layout.Rows)
+
+	var textHeight float64
+	for _, line := range sheetLines(SheetCell{}) {
+		textHeight += line.height
+	}
+	qrSize := sheet.QRSize
+	if fit := cellHeight - textHeight - 2*sheetPadding - sheetTextGap; fit < qrSize {
+		qrSize = fit
+	}
+	if fit := cellWidth - 2*sheetPadding; fit < qrSize {
+		qrSize = fit
+	}
+
+	options := gofpdf.ImageOptions{ImageType: "PNG"}
+	for _, page := range sheet.Pages {
+		doc.AddPage()
+		for r, row := range page {
+			for col, cell := range row {
+				x := sheetMargin + float64(col)*cellWidth
+				y := sheetMargin + float64(r)*cellHeight
+
+				doc.SetDrawColor(153, 153, 153)
+				doc.SetDashPattern([]float64{1, 1}, 0)
+				doc.Rect(x, y, cellWidth, cellHeight, "D")
+				doc.SetDashPattern(nil, 0)
+
+				name := "table-" + cell.TableNumber
+				doc.RegisterImageOptionsReader(name, options, bytes.NewReader(cell.QRCode))
+				doc.ImageOptions(name, x+(cellWidth-qrSize)/2, y+sheetPadding, qrSize, qrSize, false, options, 0, "")
+
+				doc.SetXY(x, y+sheetPadding+qrSize+sheetTextGap)
+				for _, line := range sheetLines(cell) {
+					doc.SetTextColor(line.gray, line.gray, line.gray)
+					fitFont(doc, line.style, line.size, tr(line.text), cellWidth)
+					doc.CellFormat(cellWidth, line.height, tr(line.text), "", 2, "C", false, 0, "")
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render QR sheet: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type sheetLine struct {
+	text   string
+	style  string
+	size   float64 // pt
+	height float64 // mm
+	gray   int
+}
+
+// sheetLines are the lines printed under a table's code.
+func sheetLines(cell SheetCell) []sheetLine {
+	return []sheetLine{
+		{"Table " + cell.TableNumber, "B", 16, 6, 0},
+		{fmt.Sprintf("%d seats", cell.Capacity), "", 10, 4, 68},
+		{"Scan to view the menu and order", "", 9, 4, 102},
+	}
+}
+
+// fitFont selects the Helvetica font at size, shrunk until text fits the
+// cell so narrow grids stay readable.
+func fitFont(doc *gofpdf.Fpdf, style string, size float64, text string, width float64) {
+	for ; size > 6; size-- {
+		doc.SetFont("Helvetica", style, size)
+		if doc.GetStringWidth(text) <= width-2*sheetPadding {
+			return
+		}
+	}
+	doc.SetFont("Helvetica", style, size)
+}
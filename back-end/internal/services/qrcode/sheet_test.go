@@ -0,0 +1,50 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"lendral3n/ordering-system/internal/models"
+	"lendral3n/ordering-system/internal/services/invoice"
+	"testing"
+)
+
+func TestGenerateTableSheetPDFNative(t *testing.T) {
+	signer, err := NewTableTokenSigner([]string{"test-secret"})
+	if err != nil {
+		t.Fatalf("NewTableTokenSigner: %v", err)
+	}
+	renderer, err := NewSheetRenderer(invoice.RendererNative)
+	if err != nil {
+		t.Fatalf("NewSheetRenderer: %v", err)
+	}
+	service := NewService("https://order.example.com", signer, renderer)
+
+	tables := make([]models.Table, 7)
+	for i := range tables {
+		tables[i] = models.Table{TableNumber: fmt.Sprintf("A%d", i+1), Capacity: 4}
+	}
+
+	tests := []struct {
+		layout SheetLayout
+		pages  int
+	}{
+		{DefaultSheetLayout(), 2},
+		{SheetLayout{Columns: 1, Rows: 1}, 7},
+		{SheetLayout{Columns: 6, Rows: 8}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%dx%d", tt.layout.Columns, tt.layout.Rows), func(t *testing.T) {
+			sheet, err := service.GenerateTableSheetPDF(tables, tt.layout)
+			if err != nil {
+				t.Fatalf("GenerateTableSheetPDF: %v", err)
+			}
+			if !bytes.HasPrefix(sheet, []byte("%PDF-")) {
+				t.Fatalf("not a PDF: %q", sheet[:min(len(sheet), 16)])
+			}
+			if pages := bytes.Count(sheet, []byte("/Type /Page\n")); pages != tt.pages {
+				t.Errorf("got %d pages, want %d", pages, tt.pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package qrcode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidTableToken = errors.New("invalid table token")
+
+// TableTokenSigner signs table numbers so a QR code can only start a session
+// for the table it was printed for.
+//
+// Keys are ordered newest first: tokens are always signed with the first key
+// and verified against all of them. To rotate, prepend a new key, regenerate
+// and reprint the QR codes, then drop the old key.
+type TableTokenSigner struct {
+	keys [][]byte
+}
+
+func NewTableTokenSigner(secrets []string) (*TableTokenSigner, error) {
+	keys := make([][]byte, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			keys = append(keys, []byte(secret))
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("at least one table token secret is required")
+	}
+
+	return &TableTokenSigner{keys: keys}, nil
+}
+
+// Sign returns a token of the form base64url(tableNumber).base64url(mac).
+func (s *TableTokenSigner) Sign(tableNumber string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(tableNumber))
+	mac := computeMAC(s.keys[0], payload)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac)
+}
+
+// Verify checks the token against every configured key and returns the
+// table number it was issued for.
+func (s *TableTokenSigner) Verify(token string) (string, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || payload == "" || sig == "" {
+		return "", ErrInvalidTableToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return "", ErrInvalidTableToken
+	}
+
+	for _, key := range s.keys {
+		if hmac.Equal(mac, computeMAC(key, payload)) {
+			tableNumber, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return "", ErrInvalidTableToken
+			}
+			return string(tableNumber), nil
+		}
+	}
+
+	return "", ErrInvalidTableToken
+}
+
+func computeMAC(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("table:" + payload))
+	return mac.Sum(nil)
+}
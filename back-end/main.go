@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -11,9 +12,13 @@ import (
 	"lendral3n/ordering-system/internal/handler"
 	"lendral3n/ordering-system/internal/models"
 	"lendral3n/ordering-system/internal/routes"
+	"lendral3n/ordering-system/internal/services/auth"
+	"lendral3n/ordering-system/internal/services/invoice"
+	"lendral3n/ordering-system/internal/services/kitchen"
 	"lendral3n/ordering-system/internal/services/media"
 	"lendral3n/ordering-system/internal/services/notification"
 	"lendral3n/ordering-system/internal/services/payment"
+	"lendral3n/ordering-system/internal/services/printer"
 	"lendral3n/ordering-system/internal/services/qrcode"
 	"strings"
 
@@ -41,6 +46,9 @@ func main() {
 	migrationModels := []interface{}{
 		&models.Table{},
 		&models.Staff{},
+		&models.StaffShift{},
+		&models.RestaurantProfile{},
+		&models.TaxRule{},
 		// Menu related - category first, then items
 		&models.MenuCategory{},
 		&models.MenuItem{},
@@ -48,8 +56,20 @@ func main() {
 		&models.CustomerSession{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderEvent{},
+		&models.OrderCharge{},
 		// Others
 		&models.Payment{},
+		&models.Refund{},
+		&models.SplitBill{},
+		&models.SplitShare{},
+		&models.SplitShareItem{},
+		&models.SessionBill{},
+		&models.PaymentEvent{},
+		&models.ReconciliationReport{},
+		&models.ReconciliationMismatch{},
+		&models.Invoice{},
+		&models.KitchenTicket{},
 		&models.MediaFile{},
 		&models.Notification{},
 		&models.InventoryLog{},
@@ -67,9 +87,47 @@ func main() {
 		log.Fatal("Failed to initialize Cloudinary:", err)
 	}
 
-	midtransService := payment.NewMidtransService(cfg)
-	qrService := qrcode.NewService(cfg.BaseURL)
+	paymentProvider, err := payment.NewProvider(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize payment provider:", err)
+	}
+	tableTokenSigner, err := qrcode.NewTableTokenSigner(cfg.TableTokenSecrets)
+	if err != nil {
+		log.Fatal("Failed to initialize table token signer:", err)
+	}
+	sheetRenderer, err := qrcode.NewSheetRenderer(cfg.InvoiceRenderer)
+	if err != nil {
+		log.Fatal("Failed to initialize QR sheet renderer:", err)
+	}
+	qrService := qrcode.NewService(cfg.BaseURL, tableTokenSigner, sheetRenderer)
+	invoiceRenderer, err := invoice.NewRenderer(cfg.InvoiceRenderer, cfg.InvoiceTemplatePath)
+	if err != nil {
+		log.Fatal("Failed to initialize invoice renderer:", err)
+	}
+	invoiceService := invoice.NewService(db, invoiceRenderer)
+	receiptPrinter, err := printer.NewSink(cfg.ReceiptPrinter)
+	if err != nil {
+		log.Fatal("Failed to initialize receipt printer:", err)
+	}
+	kitchenPrinters := make(map[string]printer.Sink)
+	for station, target := range cfg.KitchenPrinters {
+		sink, err := printer.NewSink(target)
+		if err != nil {
+			log.Fatalf("Failed to initialize %s printer: %v", station, err)
+		}
+		kitchenPrinters[station] = sink
+	}
+	var kitchenSpool printer.Sink
+	if cfg.KitchenSpoolDir != "" {
+		spool, err := printer.NewSpoolSink(cfg.KitchenSpoolDir)
+		if err != nil {
+			log.Fatal("Failed to initialize kitchen spool:", err)
+		}
+		kitchenSpool = spool
+	}
+	kitchenService := kitchen.NewService(db, kitchenPrinters, kitchenSpool, printer.Paper(cfg.KitchenPaperWidth))
 	notificationHub := notification.NewHub()
+	tokenService := auth.NewTokenService(cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
 
 	// Start notification hub
 	go notificationHub.Run()
@@ -97,7 +155,7 @@ func main() {
 	app.Use(recover.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Join(cfg.AllowedOrigins, ","),
-		AllowHeaders:     "Origin, Content-Type, Accept, X-Session-Token",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Session-Token",
 		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
 		AllowCredentials: true,
 	}))
@@ -115,15 +173,27 @@ func main() {
 	h := handlers.NewHandlers(
 		db,
 		cloudinaryService,
-		midtransService,
+		paymentProvider,
 		qrService,
+		invoiceService,
+		receiptPrinter,
+		kitchenService,
 		notificationHub,
+		tokenService,
 		cfg,
 	)
 
 	// Setup routes
 	routes.SetupRoutes(app, h)
 
+	// Start payment reconciliation
+	ctx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	if cfg.ReconcileInterval > 0 {
+		reconciler := payment.NewReconciler(db, paymentProvider, h.ApplyTransactionStatus, cfg.ReconcileInterval, cfg.ReconcilePendingAfter)
+		go reconciler.Run(ctx)
+	}
+
 	// Setup WebSocket
 	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
 		notificationHub.HandleWebSocket(c)
@@ -143,6 +213,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopBackground()
 
 	// Shutdown
 	if err := app.Shutdown(); err != nil {
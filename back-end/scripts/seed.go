@@ -33,7 +33,12 @@ func main() {
 	defer sqlDB.Close()
 
 	// Initialize services
-	qrService := qrcode.NewService(cfg.BaseURL)
+	tableTokenSigner, err := qrcode.NewTableTokenSigner(cfg.TableTokenSecrets)
+	if err != nil {
+		log.Fatal("Failed to initialize table token signer:", err)
+	}
+	// Seeding stores each table's code but never prints a sheet
+	qrService := qrcode.NewService(cfg.BaseURL, tableTokenSigner, nil)
 
 	// Seed staff users
 	if err := seedStaff(db); err != nil {
@@ -242,7 +247,7 @@ func seedMenu(db *gorm.DB) error {
 		Category        string
 		Name            string
 		Description     string
-		Price           float64
+		Price           models.Money
 		PreparationTime int
 		StockQuantity   *int
 	}{
@@ -388,7 +393,7 @@ func seedMenu(db *gorm.DB) error {
 		if err := db.Create(&menuItem).Error; err != nil {
 			return fmt.Errorf("failed to create menu item %s: %w", item.Name, err)
 		}
-		log.Printf("Created menu item: %s (Rp %.0f)", item.Name, item.Price)
+		log.Printf("Created menu item: %s (Rp %d)", item.Name, item.Price)
 	}
 
 	return nil
@@ -1,6 +1,6 @@
 // src/pages/ScanPage.js
 import React, { useState, useCallback } from 'react';
-import { useNavigate } from 'react-router-dom';
+import { useNavigate, useSearchParams } from 'react-router-dom';
 import { useDispatch, useSelector } from 'react-redux';
 import {
   Box,
@@ -24,11 +24,15 @@ import { startSession } from '../store/slices/sessionSlice';
 const ScanPage = () => {
   const navigate = useNavigate();
   const dispatch = useDispatch();
+  const [searchParams] = useSearchParams();
   const { isLoading, error } = useSelector((state) => state.session);
   
   const [showScanner, setShowScanner] = useState(false);
-  const [showForm, setShowForm] = useState(false);
-  const [tableNumber, setTableNumber] = useState('');
+  // Opening the QR code's link with the phone camera lands here with the
+  // table and its signed token already in the URL
+  const [showForm, setShowForm] = useState(!!searchParams.get('token'));
+  const [tableNumber, setTableNumber] = useState(searchParams.get('table') || '');
+  const [tableToken, setTableToken] = useState(searchParams.get('token') || '');
   const [customerInfo, setCustomerInfo] = useState({
     name: '',
     phone: '',
@@ -36,10 +40,17 @@ const ScanPage = () => {
 
   const handleScan = useCallback((result) => {
     if (result) {
-      const url = new URL(result.text);
+      let url;
+      try {
+        url = new URL(result.text);
+      } catch {
+        return;
+      }
       const table = url.searchParams.get('table');
-      if (table) {
+      const token = url.searchParams.get('token');
+      if (table && token) {
         setTableNumber(table);
+        setTableToken(token);
         setShowScanner(false);
         setShowForm(true);
       }
@@ -53,7 +64,9 @@ const ScanPage = () => {
   const handleSubmit = async (e) => {
     e.preventDefault();
     
+    // The server only trusts the table the signed token was issued for
     const result = await dispatch(startSession({
+      tableToken,
       tableNumber,
       customerName: customerInfo.name,
       customerPhone: customerInfo.phone,
@@ -64,10 +77,6 @@ const ScanPage = () => {
     }
   };
 
-  const handleManualEntry = () => {
-    setShowForm(true);
-  };
-
   return (
     <Box
       sx={{
@@ -109,18 +118,9 @@ const ScanPage = () => {
                   size="large"
                   startIcon={<QrCodeScanner />}
                   onClick={() => setShowScanner(true)}
-                  sx={{ mb: 2 }}
                 >
                   Scan QR Code
                 </Button>
-                <Button
-                  fullWidth
-                  variant="outlined"
-                  size="large"
-                  onClick={handleManualEntry}
-                >
-                  Enter Table Number Manually
-                </Button>
               </Box>
             )}
 
@@ -130,8 +130,8 @@ const ScanPage = () => {
                   fullWidth
                   label="Table Number"
                   value={tableNumber}
-                  onChange={(e) => setTableNumber(e.target.value)}
-                  required
+                  InputProps={{ readOnly: true }}
+                  helperText="Taken from the QR code on your table"
                   sx={{ mb: 2 }}
                 />
                 <TextField